@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"brunokim.xyz/advent-of-code-2019/intcode"
+)
+
+// benchmarkComputer runs the program with the inputs, configuring each
+// computer before running it.
+func benchmarkComputer(b *testing.B, input string, configure func(c *intcode.Computer), inputs ...int) {
+	program := intcode.ParseProgram(input)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c := intcode.NewComputer(program)
+		configure(c)
+		if _, err := c.RunWith(inputs...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func interpreted(c *intcode.Computer) {}
+
+func noCache(c *intcode.Computer) {
+	c.NoCache = true
+	c.NoFusion = true
+}
+
+func noFusion(c *intcode.Computer) {
+	c.NoFusion = true
+}
+
+// benchmarkConfigs runs the program with the default interpreter, without
+// instruction cache and without compare+jump fusion.
+func benchmarkConfigs(b *testing.B, input string, inputs ...int) {
+	b.Run("cached", func(b *testing.B) { benchmarkComputer(b, input, interpreted, inputs...) })
+	b.Run("nocache", func(b *testing.B) { benchmarkComputer(b, input, noCache, inputs...) })
+	b.Run("nofusion", func(b *testing.B) { benchmarkComputer(b, input, noFusion, inputs...) })
+}
+
+func benchmarkPermutations(b *testing.B, phases []int, instance func(phases ...int) (int, error)) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, comb := range permutations(phases) {
+			if _, err := instance(comb...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkDay7Part1(b *testing.B) {
+	benchmarkPermutations(b, []int{0, 1, 2, 3, 4}, day7Part1Instance)
+}
+
+func BenchmarkDay7Translated(b *testing.B) {
+	benchmarkPermutations(b, []int{0, 1, 2, 3, 4}, day7TranslatedInstance)
+}
+
+func BenchmarkDay7Part2(b *testing.B) {
+	benchmarkPermutations(b, []int{5, 6, 7, 8, 9}, day7Part2Instance)
+}
+
+func BenchmarkDay9Part1(b *testing.B) {
+	benchmarkComputer(b, day9Input, interpreted, 1)
+}
+
+func BenchmarkDay9Part2(b *testing.B) {
+	benchmarkConfigs(b, day9Input, 2)
+}
+
+func BenchmarkDay13Part1(b *testing.B) {
+	benchmarkConfigs(b, day13Input)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// lineEditor reads lines from a terminal in raw mode, echoing them to w and
+// supporting basic editing keys:
+//
+//	left, right, ^B, ^F   move the cursor
+//	home, end, ^A, ^E     move to the start or end of the line
+//	backspace, delete     delete the character before or under the cursor
+//	^U, ^K, ^W            delete to the start or end of the line, or a word
+//	up, down, ^P, ^N      browse the history
+//	^D                    end of input, if the line is empty
+type lineEditor struct {
+	r *bufio.Reader
+	w io.Writer
+	// History is browsed with the arrow keys, oldest first. Each non-empty
+	// line read is appended to it.
+	History []string
+}
+
+func newLineEditor(r io.Reader, w io.Writer) *lineEditor {
+	return &lineEditor{r: bufio.NewReader(r), w: w}
+}
+
+type lineState struct {
+	w      io.Writer
+	buf    []rune
+	cursor int
+}
+
+// redraw rewrites the line after moving back from the cursor, which was drawn
+// at old, so that any prompt before the line is kept.
+func (l *lineState) redraw(old int) {
+	if old > 0 {
+		fmt.Fprintf(l.w, "\x1b[%dD", old)
+	}
+	fmt.Fprintf(l.w, "%s\x1b[K", string(l.buf))
+	if back := len(l.buf) - l.cursor; back > 0 {
+		fmt.Fprintf(l.w, "\x1b[%dD", back)
+	}
+}
+
+func (l *lineState) set(buf []rune, cursor int) {
+	old := l.cursor
+	l.buf, l.cursor = buf, cursor
+	l.redraw(old)
+}
+
+func (l *lineState) insert(ch rune) {
+	buf := append(append(append([]rune(nil), l.buf[:l.cursor]...), ch), l.buf[l.cursor:]...)
+	l.set(buf, l.cursor+1)
+}
+
+// deleteRange removes the characters in [from, to).
+func (l *lineState) deleteRange(from, to int) {
+	if from < 0 || to > len(l.buf) || from >= to {
+		return
+	}
+	buf := append(append([]rune(nil), l.buf[:from]...), l.buf[to:]...)
+	cursor := l.cursor
+	if cursor > to {
+		cursor -= to - from
+	} else if cursor > from {
+		cursor = from
+	}
+	l.set(buf, cursor)
+}
+
+// ReadLine reads a line, returning io.EOF if input ends or ^D is typed on an
+// empty line.
+func (e *lineEditor) ReadLine() (string, error) {
+	l := &lineState{w: e.w}
+	// Index of the history entry shown, and the line being typed while
+	// browsing it.
+	index, typed := len(e.History), []rune(nil)
+	browse := func(i int) {
+		if i < 0 || i > len(e.History) || i == index {
+			return
+		}
+		if index == len(e.History) {
+			typed = l.buf
+		}
+		index = i
+		buf := typed
+		if i < len(e.History) {
+			buf = []rune(e.History[i])
+		}
+		l.set(buf, len(buf))
+	}
+	for {
+		ch, _, err := e.r.ReadRune()
+		if err == io.EOF && len(l.buf) > 0 {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch ch {
+		case '\r', '\n':
+			goto done
+		case 0x01: // ^A
+			l.set(l.buf, 0)
+		case 0x02: // ^B
+			if l.cursor > 0 {
+				l.set(l.buf, l.cursor-1)
+			}
+		case 0x04: // ^D
+			if len(l.buf) == 0 {
+				return "", io.EOF
+			}
+			l.deleteRange(l.cursor, l.cursor+1)
+		case 0x05: // ^E
+			l.set(l.buf, len(l.buf))
+		case 0x06: // ^F
+			if l.cursor < len(l.buf) {
+				l.set(l.buf, l.cursor+1)
+			}
+		case 0x08, 0x7f: // Backspace
+			l.deleteRange(l.cursor-1, l.cursor)
+		case 0x0b: // ^K
+			l.deleteRange(l.cursor, len(l.buf))
+		case 0x0e: // ^N
+			browse(index + 1)
+		case 0x10: // ^P
+			browse(index - 1)
+		case 0x15: // ^U
+			l.deleteRange(0, l.cursor)
+		case 0x17: // ^W
+			from := l.cursor
+			for from > 0 && l.buf[from-1] == ' ' {
+				from--
+			}
+			for from > 0 && l.buf[from-1] != ' ' {
+				from--
+			}
+			l.deleteRange(from, l.cursor)
+		case 0x1b: // Escape sequence
+			if err := e.escape(l, browse, index); err != nil {
+				return "", err
+			}
+		default:
+			if ch >= ' ' {
+				l.insert(ch)
+			}
+		}
+	}
+done:
+	fmt.Fprint(e.w, "\r\n")
+	line := string(l.buf)
+	if line != "" {
+		e.History = append(e.History, line)
+	}
+	return line, nil
+}
+
+// escape handles the ANSI sequences of the arrow, home, end and delete keys.
+// Unknown sequences are ignored.
+func (e *lineEditor) escape(l *lineState, browse func(int), index int) error {
+	ch, _, err := e.r.ReadRune()
+	if err != nil || (ch != '[' && ch != 'O') {
+		return err
+	}
+	ch, _, err = e.r.ReadRune()
+	if err != nil {
+		return err
+	}
+	switch ch {
+	case 'A':
+		browse(index - 1)
+	case 'B':
+		browse(index + 1)
+	case 'C':
+		if l.cursor < len(l.buf) {
+			l.set(l.buf, l.cursor+1)
+		}
+	case 'D':
+		if l.cursor > 0 {
+			l.set(l.buf, l.cursor-1)
+		}
+	case 'H':
+		l.set(l.buf, 0)
+	case 'F':
+		l.set(l.buf, len(l.buf))
+	case '3': // Delete is "\x1b[3~".
+		if ch, _, err = e.r.ReadRune(); err != nil || ch != '~' {
+			return err
+		}
+		l.deleteRange(l.cursor, l.cursor+1)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"plain", "north\nsouth\r", []string{"north", "south"}},
+		{"backspace", "nort\x7f\x7fth\n", []string{"noth"}},
+		{"arrows", "nrth\x1b[D\x1b[D\x1b[Do\n", []string{"north"}},
+		{"home and end", "orth\x01n\x05!\n", []string{"north!"}},
+		{"delete", "nxorth\x1b[H\x1b[C\x1b[3~\n", []string{"north"}},
+		{"kill", "take coin\x17\x17drop\n", []string{"drop"}},
+		{"kill to start and end", "abc\x02\x0b\x15x\n", []string{"x"}},
+		{"history", "north\nsouth\n\x1b[A\x1b[A\n\x10\x0e\n", []string{"north", "south", "north", ""}},
+		{"history keeps typed line", "north\nea\x1b[A\x1b[Bst\n", []string{"north", "east"}},
+		{"eof without newline", "north", []string{"north"}},
+		{"ctrl-d", "no\x01\x04\x04\x04\x04\x04\x04", []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := newLineEditor(strings.NewReader(test.input), ioutil.Discard)
+			got := []string{}
+			for {
+				line, err := e.ReadLine()
+				if err != nil {
+					break
+				}
+				got = append(got, line)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
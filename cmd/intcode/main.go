@@ -0,0 +1,270 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"brunokim.xyz/advent-of-code-2019/intcode"
+)
+
+type inputList []int
+
+func (l *inputList) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *inputList) Set(s string) error {
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return err
+		}
+		*l = append(*l, v)
+	}
+	return nil
+}
+
+func (l *inputList) NextInt() (int, bool) {
+	if len(*l) == 0 {
+		return 0, false
+	}
+	v := (*l)[0]
+	*l = (*l)[1:]
+	return v, true
+}
+
+type printer struct{}
+
+func (printer) PushInt(i int) {
+	fmt.Println("output:", i)
+}
+
+func cfg(args []string) error {
+	fs := flag.NewFlagSet("cfg", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: intcode cfg <program>")
+	}
+	program, err := intcode.LoadProgramFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return intcode.BuildCFG(program).WriteDOT(os.Stdout)
+}
+
+func decompile(args []string) error {
+	fs := flag.NewFlagSet("decompile", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: intcode decompile <program>")
+	}
+	program, err := intcode.LoadProgramFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return intcode.Decompile(os.Stdout, program)
+}
+
+func debug(args []string) error {
+	fs := flag.NewFlagSet("debug", flag.ExitOnError)
+	var inputs inputList
+	fs.Var(&inputs, "input", "comma-separated values fed to the program")
+	record := fs.Bool("record", false, "record execution from the start, to allow running backwards")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: intcode debug [-input 1,2,...] [-record] <program>")
+	}
+	program, err := intcode.LoadProgramFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	c := intcode.NewComputer(program)
+	if *record {
+		c.StartRecording()
+	}
+	d := intcode.NewDebugger(c, &inputs, printer{})
+	return d.REPL(os.Stdin, os.Stdout)
+}
+
+func profile(args []string) error {
+	fs := flag.NewFlagSet("profile", flag.ExitOnError)
+	var inputs inputList
+	fs.Var(&inputs, "input", "comma-separated values fed to the program")
+	pprofPath := fs.String("pprof", "", "write a pprof profile to this file")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: intcode profile [-input 1,2,...] [-pprof file] <program>")
+	}
+	program, err := intcode.LoadProgramFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	c := intcode.NewComputer(program)
+	profiler := intcode.NewProfiler()
+	c.Tracer = profiler
+	if err := c.Run(&inputs, printer{}); err != nil {
+		return err
+	}
+	if err := profiler.Annotate(os.Stdout, program); err != nil {
+		return err
+	}
+	fmt.Println()
+	if err := profiler.WriteOpcodeCounts(os.Stdout); err != nil {
+		return err
+	}
+	fmt.Printf("coverage: %.1f%%\n", 100*profiler.Coverage(program))
+	if *pprofPath == "" {
+		return nil
+	}
+	f, err := os.Create(*pprofPath)
+	if err != nil {
+		return err
+	}
+	if err := profiler.WritePprof(f, program); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func play(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	historyPath := fs.String("history", "", "replay commands from this file and append new ones to it")
+	restorePath := fs.String("restore", "", "start from a state saved with /save, restarting the history from it")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: intcode play [-history file] [-restore file] <program>")
+	}
+	program, err := intcode.LoadProgramFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	s := intcode.NewSession(intcode.NewComputer(program))
+	if *historyPath != "" {
+		f, err := os.OpenFile(*historyPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		// A restored state replaces the history, which may lead elsewhere.
+		if *restorePath == "" {
+			if err := s.Replay(f); err != nil {
+				return err
+			}
+		}
+		s.Record = f
+	}
+	if *restorePath != "" {
+		if err := s.Restore(*restorePath); err != nil {
+			return err
+		}
+	}
+	if restore, err := makeRaw(int(os.Stdin.Fd())); err == nil {
+		defer restore()
+		return s.PlayLines(newLineEditor(os.Stdin, os.Stdout), os.Stdout)
+	}
+	return s.Play(os.Stdin, os.Stdout)
+}
+
+// loadConst returns the program in the string constant name of a Go file.
+func loadConst(path, name string) ([]int, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vspec := spec.(*ast.ValueSpec)
+			for i, ident := range vspec.Names {
+				if ident.Name != name || i >= len(vspec.Values) {
+					continue
+				}
+				lit, ok := vspec.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return nil, fmt.Errorf("%s: %s is not a string literal", path, name)
+				}
+				src, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					return nil, err
+				}
+				program, err := intcode.LoadProgram(strings.NewReader(src))
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+				}
+				return program, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: const %s not found", path, name)
+}
+
+func translate(args []string) error {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+	pkg := fs.String("package", "main", "package of the generated file")
+	name := fs.String("func", "translated", "name of the generated function")
+	constName := fs.String("const", "", "read the program from this string constant of a Go file")
+	outPath := fs.String("o", "", "write to this file instead of stdout")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: intcode translate [-package p] [-func f] [-const c] [-o file] <program>")
+	}
+	var program []int
+	var err error
+	if *constName != "" {
+		program, err = loadConst(fs.Arg(0), *constName)
+	} else {
+		program, err = intcode.LoadProgramFile(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	if *outPath == "" {
+		return intcode.Translate(os.Stdout, program, *pkg, *name)
+	}
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	if err := intcode.Translate(f, program, *pkg, *name); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var commands = map[string]func([]string) error{
+	"cfg":       cfg,
+	"debug":     debug,
+	"decompile": decompile,
+	"play":      play,
+	"profile":   profile,
+	"translate": translate,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: intcode <command> [args]")
+		var names []string
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(os.Stderr, "commands:", strings.Join(names, ", "))
+		os.Exit(2)
+	}
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "errors"
+
+// makeRaw is only supported on Linux, where the line editor is used.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("Raw terminal mode not supported")
+}
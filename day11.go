@@ -122,7 +122,7 @@ func (r *robot) newPosition() coord {
 	case east:
 		return coord{r.pos.x + 1, r.pos.y}
 	default:
-		panic("Invalid direction: " + string(r.dir))
+		panic(fmt.Sprintf("Invalid direction: %d", r.dir))
 	}
 }
 
@@ -133,7 +133,7 @@ func (r *robot) newDirection(i int) direction {
 	case clockwise:
 		return nextClockwise[r.dir]
 	default:
-		panic("Invalid rotation direction: " + string(i))
+		panic(fmt.Sprintf("Invalid rotation direction: %d", i))
 	}
 }
 
@@ -151,7 +151,7 @@ func (r *robot) PushInt(i int) {
 		r.pos = r.newPosition()
 		r.state = painting
 	default:
-		panic("Invalid state: " + string(r.state))
+		panic(fmt.Sprintf("Invalid state: %d", r.state))
 	}
 }
 
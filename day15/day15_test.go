@@ -0,0 +1,19 @@
+package day15
+
+import (
+	"reflect"
+	"testing"
+
+	"brunokim.xyz/advent-of-code-2019/intcode"
+)
+
+func TestDisassembleRoundTrip(t *testing.T) {
+	program := intcode.ParseProgram(day15Input)
+	got, err := intcode.Assemble(intcode.DisassembleString(program))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, program) {
+		t.Errorf("program changed after round-trip")
+	}
+}
@@ -2,25 +2,105 @@ package main
 
 import (
 	"fmt"
-	"sync"
 
 	"brunokim.xyz/advent-of-code-2019/intcode"
 )
 
+//go:generate go run ./cmd/intcode translate -func day7Translated -const day7Input -o day7_translated.go day7.go
+
+var ampNames = [...]string{"A", "B", "C", "D", "E"}
+
+func newAmplifiers(input string, phases ...int) (*intcode.Network, error) {
+	program := intcode.ParseProgram(input)
+	n := intcode.NewNetwork()
+	for i, phase := range phases {
+		inputs := []int{phase}
+		if i == 0 {
+			inputs = append(inputs, 0)
+		}
+		if err := n.AddNode(ampNames[i], program, inputs...); err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			if err := n.Connect(ampNames[i-1], ampNames[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func lastAmpOutput(results map[string]*intcode.NodeResult, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	for _, name := range ampNames {
+		if err := results[name].Err; err != nil {
+			return 0, fmt.Errorf("Amp %s: %v", name, err)
+		}
+	}
+	outputs := results[ampNames[len(ampNames)-1]].Outputs
+	if len(outputs) == 0 {
+		return 0, fmt.Errorf("No output generated")
+	}
+	return outputs[len(outputs)-1], nil
+}
+
 func day7Part1Instance(phases ...int) (int, error) {
-	input := 0
+	return runAmplifiers(day7Input, phases...)
+}
+
+// runAmplifiers runs amplifiers in series, each of which must generate
+// exactly one output.
+func runAmplifiers(input string, phases ...int) (int, error) {
+	n, err := newAmplifiers(input, phases...)
+	if err != nil {
+		return 0, err
+	}
+	results, err := n.Run(intcode.Serial)
+	output, err := lastAmpOutput(results, err)
+	if err != nil {
+		return 0, err
+	}
+	for _, name := range ampNames[:len(phases)] {
+		if outputs := results[name].Outputs; len(outputs) != 1 {
+			return 0, fmt.Errorf("Amp %s: %d outputs generated, want 1: %v", name, len(outputs), outputs)
+		}
+	}
+	return output, nil
+}
+
+// intQueue is a FIFO of ints, used as input and output of translated code.
+type intQueue []int
+
+func (q *intQueue) NextInt() (int, bool) {
+	if len(*q) == 0 {
+		return 0, false
+	}
+	v := (*q)[0]
+	*q = (*q)[1:]
+	return v, true
+}
+
+func (q *intQueue) PushInt(v int) {
+	*q = append(*q, v)
+}
+
+// day7TranslatedInstance is like day7Part1Instance, but running the
+// translated program in sequence.
+func day7TranslatedInstance(phases ...int) (int, error) {
+	signal := 0
 	for i, phase := range phases {
-		amp := intcode.NewComputer(intcode.ParseProgram(day7Input))
-		outputs, err := amp.RunWith(phase, input)
-		if err != nil {
-			return 0, fmt.Errorf("Amp %d: %v", i+1, err)
+		var out intQueue
+		if err := day7Translated(&intQueue{phase, signal}, &out); err != nil {
+			return 0, fmt.Errorf("Amp %s: %v", ampNames[i], err)
 		}
-		if len(outputs) != 1 {
-			return 0, fmt.Errorf("More than 1 output generated: %v", outputs)
+		if len(out) != 1 {
+			return 0, fmt.Errorf("Amp %s: %d outputs generated, want 1: %v", ampNames[i], len(out), out)
 		}
-		input = outputs[0]
+		signal = out[0]
 	}
-	return input, nil
+	return signal, nil
 }
 
 func permutations(xs []int) [][]int {
@@ -42,63 +122,15 @@ func permutations(xs []int) [][]int {
 	return cs
 }
 
-type pipe struct {
-	id        int
-	lastInput int
-	ch        chan int
-}
-
-func newPipe(id int) *pipe {
-	return &pipe{
-		id:        id,
-		lastInput: -1,
-		ch:        make(chan int, 1),
-	}
-}
-
-func (p *pipe) NextInt() (int, bool) {
-	i, ok := <-p.ch
-	if !ok {
-		return 0, false
-	}
-	return i, true
-}
-
-func (p *pipe) PushInt(i int) {
-	p.lastInput = i
-	p.ch <- i
-}
-
 func day7Part2Instance(phases ...int) (int, error) {
-	n := len(phases)
-	pipes := make([]*pipe, n)
-	amps := make([]*intcode.Computer, n)
-	errs := make([]error, n)
-	for i := 0; i < n; i++ {
-		pipes[i] = newPipe(i + 1)
-		amps[i] = intcode.NewComputer(intcode.ParseProgram(day7Input))
-	}
-	var wg sync.WaitGroup
-	for i := 0; i < n; i++ {
-		pipes[i].PushInt(phases[i])
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-			errs[i] = amps[i].Run(pipes[i], pipes[(i+1)%n])
-		}(i)
+	n, err := newAmplifiers(day7Input, phases...)
+	if err != nil {
+		return 0, err
 	}
-	pipes[0].PushInt(0)
-	wg.Wait()
-	for i, err := range errs {
-		if err != error(nil) {
-			return 0, fmt.Errorf("Amp #%d: %v", i+1, err)
-		}
+	if err := n.Connect(ampNames[len(phases)-1], ampNames[0]); err != nil {
+		return 0, err
 	}
-	for _, pipe := range pipes {
-		close(pipe.ch)
-	}
-	output := pipes[0].lastInput
-	return output, nil
+	return lastAmpOutput(n.Run(intcode.Concurrent))
 }
 
 func day7() {
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunAmplifiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		program string
+		want    int
+		err     string
+	}{
+		{"increment", "3,0,3,0,1001,0,1,0,4,0,99", 5, ""},
+		{"no output", "3,0,3,0,99", 0, "Deadlock"},
+		{"two outputs", "3,0,3,0,4,0,4,0,99", 0, "Amp A: 2 outputs generated, want 1: [0 0]"},
+	}
+	for _, test := range tests {
+		got, err := runAmplifiers(test.program, 0, 1, 2, 3, 4)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: got %d, %v, want error %q", test.name, got, err, test.err)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("%s: got %d, %v, want %d", test.name, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,738 @@
+// Code generated by intcode translate. DO NOT EDIT.
+
+package main
+
+import "brunokim.xyz/advent-of-code-2019/intcode"
+
+var day7TranslatedProgram = [...]int{
+	3, 8, 1001, 8, 10, 8, 105, 1, 0, 0, 21, 42, 67, 84, 97, 118,
+	199, 280, 361, 442, 99999, 3, 9, 101, 4, 9, 9, 102, 5, 9, 9, 101,
+	2, 9, 9, 1002, 9, 2, 9, 4, 9, 99, 3, 9, 101, 5, 9, 9,
+	102, 5, 9, 9, 1001, 9, 5, 9, 102, 3, 9, 9, 1001, 9, 2, 9,
+	4, 9, 99, 3, 9, 1001, 9, 5, 9, 1002, 9, 2, 9, 1001, 9, 5,
+	9, 4, 9, 99, 3, 9, 1001, 9, 5, 9, 1002, 9, 3, 9, 4, 9,
+	99, 3, 9, 102, 4, 9, 9, 101, 4, 9, 9, 102, 2, 9, 9, 101,
+	3, 9, 9, 4, 9, 99, 3, 9, 102, 2, 9, 9, 4, 9, 3, 9,
+	1002, 9, 2, 9, 4, 9, 3, 9, 1001, 9, 2, 9, 4, 9, 3, 9,
+	102, 2, 9, 9, 4, 9, 3, 9, 102, 2, 9, 9, 4, 9, 3, 9,
+	1001, 9, 2, 9, 4, 9, 3, 9, 1002, 9, 2, 9, 4, 9, 3, 9,
+	102, 2, 9, 9, 4, 9, 3, 9, 1001, 9, 2, 9, 4, 9, 3, 9,
+	101, 2, 9, 9, 4, 9, 99, 3, 9, 1001, 9, 1, 9, 4, 9, 3,
+	9, 101, 2, 9, 9, 4, 9, 3, 9, 1001, 9, 2, 9, 4, 9, 3,
+	9, 1002, 9, 2, 9, 4, 9, 3, 9, 101, 2, 9, 9, 4, 9, 3,
+	9, 1002, 9, 2, 9, 4, 9, 3, 9, 102, 2, 9, 9, 4, 9, 3,
+	9, 1002, 9, 2, 9, 4, 9, 3, 9, 101, 1, 9, 9, 4, 9, 3,
+	9, 101, 2, 9, 9, 4, 9, 99, 3, 9, 101, 1, 9, 9, 4, 9,
+	3, 9, 1001, 9, 1, 9, 4, 9, 3, 9, 1002, 9, 2, 9, 4, 9,
+	3, 9, 1002, 9, 2, 9, 4, 9, 3, 9, 1002, 9, 2, 9, 4, 9,
+	3, 9, 1001, 9, 2, 9, 4, 9, 3, 9, 102, 2, 9, 9, 4, 9,
+	3, 9, 102, 2, 9, 9, 4, 9, 3, 9, 101, 2, 9, 9, 4, 9,
+	3, 9, 1001, 9, 2, 9, 4, 9, 99, 3, 9, 102, 2, 9, 9, 4,
+	9, 3, 9, 102, 2, 9, 9, 4, 9, 3, 9, 1001, 9, 2, 9, 4,
+	9, 3, 9, 102, 2, 9, 9, 4, 9, 3, 9, 1001, 9, 2, 9, 4,
+	9, 3, 9, 102, 2, 9, 9, 4, 9, 3, 9, 102, 2, 9, 9, 4,
+	9, 3, 9, 101, 1, 9, 9, 4, 9, 3, 9, 1001, 9, 2, 9, 4,
+	9, 3, 9, 1002, 9, 2, 9, 4, 9, 99, 3, 9, 101, 1, 9, 9,
+	4, 9, 3, 9, 101, 1, 9, 9, 4, 9, 3, 9, 102, 2, 9, 9,
+	4, 9, 3, 9, 1001, 9, 2, 9, 4, 9, 3, 9, 1001, 9, 2, 9,
+	4, 9, 3, 9, 1002, 9, 2, 9, 4, 9, 3, 9, 101, 1, 9, 9,
+	4, 9, 3, 9, 102, 2, 9, 9, 4, 9, 3, 9, 1001, 9, 1, 9,
+	4, 9, 3, 9, 1001, 9, 2, 9, 4, 9, 99,
+}
+
+// day7TranslatedCode marks the words compiled into day7Translated, which can't be written.
+var day7TranslatedCode = func() []bool {
+	code := make([]bool, len(day7TranslatedProgram))
+	for _, r := range [][2]int{
+		{0, 8},
+		{21, 523},
+	} {
+		for i := r[0]; i < r[1]; i++ {
+			code[i] = true
+		}
+	}
+	return code
+}()
+
+func day7Translated(in intcode.IntReader, out intcode.IntWriter) error {
+	mem := make([]int, len(day7TranslatedProgram)+4096)
+	copy(mem, day7TranslatedProgram[:])
+	pc, rb := 0, 0
+	for {
+		switch pc {
+		case 0:
+			// 0: in [8]
+			if v, ok := in.NextInt(); ok {
+				mem[8] = v
+			} else {
+				return day7TranslatedFallback(mem, 0, rb, in, out)
+			}
+			// 2: add [8], #10, [8]
+			mem[8] = mem[8] + 10
+			// 6: jinz #1, [0]
+			if uint(mem[8]) >= uint(len(mem)) {
+				return day7TranslatedFallback(mem, 6, rb, in, out)
+			}
+			pc = mem[mem[8]]
+		case 21:
+			// 21: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 21, rb, in, out)
+			}
+			// 23: add #4, [9], [9]
+			mem[9] = 4 + mem[9]
+			// 27: mul #5, [9], [9]
+			mem[9] = 5 * mem[9]
+			// 31: add #2, [9], [9]
+			mem[9] = 2 + mem[9]
+			// 35: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 39: out [9]
+			out.PushInt(mem[9])
+			// 41: halt
+			return nil
+		case 42:
+			// 42: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 42, rb, in, out)
+			}
+			// 44: add #5, [9], [9]
+			mem[9] = 5 + mem[9]
+			// 48: mul #5, [9], [9]
+			mem[9] = 5 * mem[9]
+			// 52: add [9], #5, [9]
+			mem[9] = mem[9] + 5
+			// 56: mul #3, [9], [9]
+			mem[9] = 3 * mem[9]
+			// 60: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 64: out [9]
+			out.PushInt(mem[9])
+			// 66: halt
+			return nil
+		case 67:
+			// 67: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 67, rb, in, out)
+			}
+			// 69: add [9], #5, [9]
+			mem[9] = mem[9] + 5
+			// 73: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 77: add [9], #5, [9]
+			mem[9] = mem[9] + 5
+			// 81: out [9]
+			out.PushInt(mem[9])
+			// 83: halt
+			return nil
+		case 84:
+			// 84: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 84, rb, in, out)
+			}
+			// 86: add [9], #5, [9]
+			mem[9] = mem[9] + 5
+			// 90: mul [9], #3, [9]
+			mem[9] = mem[9] * 3
+			// 94: out [9]
+			out.PushInt(mem[9])
+			// 96: halt
+			return nil
+		case 97:
+			// 97: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 97, rb, in, out)
+			}
+			fallthrough
+		case 99:
+			// 99: mul #4, [9], [9]
+			mem[9] = 4 * mem[9]
+			pc = 103
+		case 101:
+			// 101: base [9]
+			rb += mem[9]
+			pc = 103
+		case 102:
+			// 102: base [101]
+			rb += mem[101]
+			pc = 104
+		case 103:
+			// 103: add #4, [9], [9]
+			mem[9] = 4 + mem[9]
+			pc = 107
+		case 104:
+			// 104: out [9]
+			out.PushInt(mem[9])
+			// 106: base [102]
+			rb += mem[102]
+			pc = 108
+		case 107:
+			// 107: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			pc = 111
+		case 108:
+			// 108: mul [9], [9], [101]
+			mem[101] = mem[9] * mem[9]
+			return day7TranslatedFallback(mem, 112, rb, in, out)
+		case 111:
+			// 111: add #3, [9], [9]
+			mem[9] = 3 + mem[9]
+			pc = 115
+		case 112:
+			// 112: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 112, rb, in, out)
+			}
+			// 114: base [4]
+			rb += mem[4]
+			pc = 116
+		case 115:
+			// 115: out [9]
+			out.PushInt(mem[9])
+			pc = 117
+		case 116:
+			// 116: base [99]
+			rb += mem[99]
+			pc = 118
+		case 117:
+			// 117: halt
+			return nil
+		case 118:
+			// 118: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 118, rb, in, out)
+			}
+			// 120: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 124: out [9]
+			out.PushInt(mem[9])
+			// 126: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 126, rb, in, out)
+			}
+			// 128: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 132: out [9]
+			out.PushInt(mem[9])
+			// 134: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 134, rb, in, out)
+			}
+			// 136: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 140: out [9]
+			out.PushInt(mem[9])
+			// 142: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 142, rb, in, out)
+			}
+			// 144: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 148: out [9]
+			out.PushInt(mem[9])
+			// 150: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 150, rb, in, out)
+			}
+			// 152: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 156: out [9]
+			out.PushInt(mem[9])
+			// 158: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 158, rb, in, out)
+			}
+			// 160: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 164: out [9]
+			out.PushInt(mem[9])
+			// 166: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 166, rb, in, out)
+			}
+			// 168: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 172: out [9]
+			out.PushInt(mem[9])
+			// 174: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 174, rb, in, out)
+			}
+			// 176: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 180: out [9]
+			out.PushInt(mem[9])
+			// 182: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 182, rb, in, out)
+			}
+			// 184: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 188: out [9]
+			out.PushInt(mem[9])
+			// 190: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 190, rb, in, out)
+			}
+			// 192: add #2, [9], [9]
+			mem[9] = 2 + mem[9]
+			// 196: out [9]
+			out.PushInt(mem[9])
+			// 198: halt
+			return nil
+		case 199:
+			// 199: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 199, rb, in, out)
+			}
+			// 201: add [9], #1, [9]
+			mem[9] = mem[9] + 1
+			// 205: out [9]
+			out.PushInt(mem[9])
+			// 207: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 207, rb, in, out)
+			}
+			// 209: add #2, [9], [9]
+			mem[9] = 2 + mem[9]
+			// 213: out [9]
+			out.PushInt(mem[9])
+			// 215: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 215, rb, in, out)
+			}
+			// 217: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 221: out [9]
+			out.PushInt(mem[9])
+			// 223: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 223, rb, in, out)
+			}
+			// 225: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 229: out [9]
+			out.PushInt(mem[9])
+			// 231: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 231, rb, in, out)
+			}
+			// 233: add #2, [9], [9]
+			mem[9] = 2 + mem[9]
+			// 237: out [9]
+			out.PushInt(mem[9])
+			// 239: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 239, rb, in, out)
+			}
+			// 241: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 245: out [9]
+			out.PushInt(mem[9])
+			// 247: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 247, rb, in, out)
+			}
+			// 249: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 253: out [9]
+			out.PushInt(mem[9])
+			// 255: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 255, rb, in, out)
+			}
+			// 257: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 261: out [9]
+			out.PushInt(mem[9])
+			// 263: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 263, rb, in, out)
+			}
+			// 265: add #1, [9], [9]
+			mem[9] = 1 + mem[9]
+			// 269: out [9]
+			out.PushInt(mem[9])
+			// 271: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 271, rb, in, out)
+			}
+			// 273: add #2, [9], [9]
+			mem[9] = 2 + mem[9]
+			// 277: out [9]
+			out.PushInt(mem[9])
+			// 279: halt
+			return nil
+		case 280:
+			// 280: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 280, rb, in, out)
+			}
+			// 282: add #1, [9], [9]
+			mem[9] = 1 + mem[9]
+			// 286: out [9]
+			out.PushInt(mem[9])
+			// 288: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 288, rb, in, out)
+			}
+			// 290: add [9], #1, [9]
+			mem[9] = mem[9] + 1
+			// 294: out [9]
+			out.PushInt(mem[9])
+			// 296: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 296, rb, in, out)
+			}
+			// 298: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 302: out [9]
+			out.PushInt(mem[9])
+			// 304: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 304, rb, in, out)
+			}
+			// 306: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 310: out [9]
+			out.PushInt(mem[9])
+			// 312: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 312, rb, in, out)
+			}
+			// 314: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 318: out [9]
+			out.PushInt(mem[9])
+			// 320: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 320, rb, in, out)
+			}
+			// 322: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 326: out [9]
+			out.PushInt(mem[9])
+			// 328: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 328, rb, in, out)
+			}
+			// 330: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 334: out [9]
+			out.PushInt(mem[9])
+			// 336: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 336, rb, in, out)
+			}
+			// 338: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 342: out [9]
+			out.PushInt(mem[9])
+			// 344: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 344, rb, in, out)
+			}
+			// 346: add #2, [9], [9]
+			mem[9] = 2 + mem[9]
+			// 350: out [9]
+			out.PushInt(mem[9])
+			// 352: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 352, rb, in, out)
+			}
+			// 354: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 358: out [9]
+			out.PushInt(mem[9])
+			// 360: halt
+			return nil
+		case 361:
+			// 361: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 361, rb, in, out)
+			}
+			// 363: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 367: out [9]
+			out.PushInt(mem[9])
+			// 369: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 369, rb, in, out)
+			}
+			// 371: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 375: out [9]
+			out.PushInt(mem[9])
+			// 377: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 377, rb, in, out)
+			}
+			// 379: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 383: out [9]
+			out.PushInt(mem[9])
+			// 385: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 385, rb, in, out)
+			}
+			// 387: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 391: out [9]
+			out.PushInt(mem[9])
+			// 393: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 393, rb, in, out)
+			}
+			// 395: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 399: out [9]
+			out.PushInt(mem[9])
+			// 401: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 401, rb, in, out)
+			}
+			// 403: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 407: out [9]
+			out.PushInt(mem[9])
+			// 409: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 409, rb, in, out)
+			}
+			// 411: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 415: out [9]
+			out.PushInt(mem[9])
+			// 417: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 417, rb, in, out)
+			}
+			// 419: add #1, [9], [9]
+			mem[9] = 1 + mem[9]
+			// 423: out [9]
+			out.PushInt(mem[9])
+			// 425: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 425, rb, in, out)
+			}
+			// 427: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 431: out [9]
+			out.PushInt(mem[9])
+			// 433: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 433, rb, in, out)
+			}
+			// 435: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 439: out [9]
+			out.PushInt(mem[9])
+			// 441: halt
+			return nil
+		case 442:
+			// 442: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 442, rb, in, out)
+			}
+			// 444: add #1, [9], [9]
+			mem[9] = 1 + mem[9]
+			// 448: out [9]
+			out.PushInt(mem[9])
+			// 450: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 450, rb, in, out)
+			}
+			// 452: add #1, [9], [9]
+			mem[9] = 1 + mem[9]
+			// 456: out [9]
+			out.PushInt(mem[9])
+			// 458: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 458, rb, in, out)
+			}
+			// 460: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 464: out [9]
+			out.PushInt(mem[9])
+			// 466: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 466, rb, in, out)
+			}
+			// 468: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 472: out [9]
+			out.PushInt(mem[9])
+			// 474: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 474, rb, in, out)
+			}
+			// 476: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 480: out [9]
+			out.PushInt(mem[9])
+			// 482: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 482, rb, in, out)
+			}
+			// 484: mul [9], #2, [9]
+			mem[9] = mem[9] * 2
+			// 488: out [9]
+			out.PushInt(mem[9])
+			// 490: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 490, rb, in, out)
+			}
+			// 492: add #1, [9], [9]
+			mem[9] = 1 + mem[9]
+			// 496: out [9]
+			out.PushInt(mem[9])
+			// 498: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 498, rb, in, out)
+			}
+			// 500: mul #2, [9], [9]
+			mem[9] = 2 * mem[9]
+			// 504: out [9]
+			out.PushInt(mem[9])
+			// 506: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 506, rb, in, out)
+			}
+			// 508: add [9], #1, [9]
+			mem[9] = mem[9] + 1
+			// 512: out [9]
+			out.PushInt(mem[9])
+			// 514: in [9]
+			if v, ok := in.NextInt(); ok {
+				mem[9] = v
+			} else {
+				return day7TranslatedFallback(mem, 514, rb, in, out)
+			}
+			// 516: add [9], #2, [9]
+			mem[9] = mem[9] + 2
+			// 520: out [9]
+			out.PushInt(mem[9])
+			// 522: halt
+			return nil
+		default:
+			return day7TranslatedFallback(mem, pc, rb, in, out)
+		}
+	}
+}
+
+// day7TranslatedFallback resumes in the interpreter from the given state.
+func day7TranslatedFallback(mem []int, pc, rb int, in intcode.IntReader, out intcode.IntWriter) error {
+	c := intcode.NewComputer(nil)
+	c.Restore(&intcode.Snapshot{Memory: mem, InstructionPointer: pc, RelativeBase: rb})
+	return c.Run(in, out)
+}
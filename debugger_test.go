@@ -0,0 +1,167 @@
+package main
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"brunokim.xyz/advent-of-code-2019/intcode"
+)
+
+// countToFive increments [20] until it's 5, keeping [20] < 5 in [21], and
+// outputs it.
+const countToFive = "1001,20,1,20,1007,20,5,21,1005,21,0,4,20,99,0,0,0,0,0,0,0,0"
+
+type outputs struct {
+	values []int
+}
+
+func (o *outputs) PushInt(v int) {
+	o.values = append(o.values, v)
+}
+
+type noInput struct{}
+
+func (noInput) NextInt() (int, bool) { return 0, false }
+
+func newCountDebugger() (*intcode.Debugger, *outputs) {
+	out := &outputs{}
+	return intcode.NewDebugger(intcode.NewComputer(intcode.ParseProgram(countToFive)), noInput{}, out), out
+}
+
+func TestDebuggerBreakpoints(t *testing.T) {
+	d, out := newCountDebugger()
+	d.SetBreakpoint(4)
+	for i := 1; i <= 2; i++ {
+		stop, err := d.Continue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (intcode.Stop{Reason: intcode.StopBreakpoint, Address: 4}); stop != want {
+			t.Fatalf("got %v, want %v", stop, want)
+		}
+		if v := d.Peek(20); v != i {
+			t.Errorf("[20] is %d at breakpoint #%d", v, i)
+		}
+	}
+	d.ClearBreakpoint(4)
+	d.SetOpcodeBreakpoint(intcode.Output)
+	stop, err := d.Continue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (intcode.Stop{Reason: intcode.StopBreakpoint, Address: 11}); stop != want {
+		t.Fatalf("got %v, want %v", stop, want)
+	}
+	if stop, err = d.Continue(); err != nil || stop.Reason != intcode.StopHalt {
+		t.Fatalf("got %v, %v, want halt", stop, err)
+	}
+	if want := []int{5}; !reflect.DeepEqual(out.values, want) {
+		t.Errorf("outputs are %v, want %v", out.values, want)
+	}
+}
+
+func TestDebuggerWatchpoints(t *testing.T) {
+	d, _ := newCountDebugger()
+	d.SetWatchpoint(21)
+	stop, err := d.Continue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := intcode.Stop{Reason: intcode.StopWatchpoint, Address: 4, Watched: 21, Old: 0, New: 1}
+	if stop != want {
+		t.Fatalf("got %v, want %v", stop, want)
+	}
+	// [21] only changes again when the loop ends.
+	stop, err = d.Continue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = intcode.Stop{Reason: intcode.StopWatchpoint, Address: 4, Watched: 21, Old: 1, New: 0}
+	if stop != want {
+		t.Fatalf("got %v, want %v", stop, want)
+	}
+	if v := d.Peek(20); v != 5 {
+		t.Errorf("[20] is %d, want 5", v)
+	}
+}
+
+func TestDebuggerRunTo(t *testing.T) {
+	c := intcode.NewComputer(intcode.ParseProgram(countToFive))
+	d := intcode.NewDebugger(c, noInput{}, &outputs{})
+	if _, err := d.RunTo(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.RunTo(1); err == nil {
+		t.Errorf("RunTo ran backwards without recording")
+	}
+
+	c = intcode.NewComputer(intcode.ParseProgram(countToFive))
+	c.StartRecording()
+	d = intcode.NewDebugger(c, noInput{}, &outputs{})
+	d.SetBreakpoint(4)
+	d.SetWatchpoint(20)
+	for _, count := range []int{7, 2, 10, 0} {
+		if _, err := d.RunTo(count); err != nil {
+			t.Fatalf("RunTo(%d): %v", count, err)
+		}
+		if got := c.InstructionCount(); got != count {
+			t.Errorf("RunTo(%d) stopped at count %d", count, got)
+		}
+	}
+	if stop, err := d.RunTo(100); err != nil || stop.Reason != intcode.StopHalt {
+		t.Errorf("RunTo past the end: got %v, %v, want halt", stop, err)
+	}
+}
+
+func TestDebuggerREPL(t *testing.T) {
+	d, _ := newCountDebugger()
+	commands := []string{
+		"b 4", "c", "x 20", "d 4",
+		"w 21", "c", "u 21",
+		"b out", "b 8", "l 8 2", "d 8", "c",
+		"r", "ip 0", "rb 7", "regs",
+		"poke 20 -1", "x 20 2",
+		"bogus", "step x", "", "b",
+		"q", "s",
+	}
+	var w strings.Builder
+	if err := d.REPL(strings.NewReader(strings.Join(commands, "\n")), &w); err != nil {
+		t.Fatal(err)
+	}
+	got := w.String()
+	for _, want := range []string{
+		"breakpoint @ 4\n4: ",
+		"[20] 1\n",
+		"watchpoint @ 4: [21] 0 -> 1\n",
+		"* 8: jinz [21], #0\n  11: out [20]\n",
+		"breakpoint @ 11\n11: out [20]",
+		"ip=11 rb=0\n",
+		"ip=0 rb=7\n",
+		"[20] -1\n[21] 0\n",
+		"error: Unknown command \"bogus\", type 'help' for a list\n",
+		"error: b expects one argument\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	// The empty line repeats the failed step.
+	if n := strings.Count(got, "error: Invalid number: \"x\""); n != 2 {
+		t.Errorf("got %d invalid number errors, want 2 in:\n%s", n, got)
+	}
+	if r := d.Registers(); r.InstructionPointer != 0 {
+		t.Errorf("executed commands after quit: %v", r)
+	}
+}
+
+func TestDebuggerREPLDetach(t *testing.T) {
+	d, out := newCountDebugger()
+	if err := d.REPL(strings.NewReader("s 3\ndetach\n"), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{5}; !reflect.DeepEqual(out.values, want) {
+		t.Errorf("outputs are %v, want %v", out.values, want)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"brunokim.xyz/advent-of-code-2019/intcode"
+)
+
+func TestDisassembleRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"day7", day7Input},
+		{"day9", day9Input},
+		{"day11", day11Input},
+		{"day13", day13Input},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			program := intcode.ParseProgram(test.input)
+			src := intcode.DisassembleString(program)
+			got, err := intcode.Assemble(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, program) {
+				t.Errorf("program changed after round-trip")
+			}
+		})
+	}
+}
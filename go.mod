@@ -1,3 +1,3 @@
 module brunokim.xyz/advent-of-code-2019
 
-go 1.12
+go 1.17
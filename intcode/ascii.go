@@ -0,0 +1,100 @@
+package intcode
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// TextReader reads inputs as the byte values of a text, such as commands
+// terminated by newlines. Once the text is consumed, NextInt returns false and
+// Err returns io.EOF or the error from the underlying reader.
+type TextReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func NewTextReader(r io.Reader) *TextReader {
+	return &TextReader{r: bufio.NewReader(r)}
+}
+
+func NewStringReader(s string) *TextReader {
+	return NewTextReader(strings.NewReader(s))
+}
+
+func (r *TextReader) NextInt() (int, bool) {
+	if r.err != nil {
+		return 0, false
+	}
+	b, err := r.r.ReadByte()
+	if err != nil {
+		r.err = err
+		return 0, false
+	}
+	return int(b), true
+}
+
+func (r *TextReader) Err() error {
+	return r.err
+}
+
+// maxASCII is the largest value written as a character by TextWriter.
+const maxASCII = 127
+
+// TextWriter collects outputs as ASCII text. Values outside of the ASCII
+// range, usually a puzzle's final answer, are kept apart in Answers.
+type TextWriter struct {
+	text    strings.Builder
+	Answers []int
+}
+
+func NewTextWriter() *TextWriter {
+	return &TextWriter{}
+}
+
+func (w *TextWriter) PushInt(i int) {
+	if i < 0 || i > maxASCII {
+		w.Answers = append(w.Answers, i)
+		return
+	}
+	w.text.WriteByte(byte(i))
+}
+
+// String returns the text written so far.
+func (w *TextWriter) String() string {
+	return w.text.String()
+}
+
+// Answer returns the last value written outside of the ASCII range, if any.
+func (w *TextWriter) Answer() (int, bool) {
+	if len(w.Answers) == 0 {
+		return 0, false
+	}
+	return w.Answers[len(w.Answers)-1], true
+}
+
+// RunText runs the program with input as ASCII, returning the text output and
+// the non-ASCII values. It fails with an InputExhaustedError if the program
+// needs more input than given. The output produced until an error is also
+// returned.
+func (c *Computer) RunText(input string) (string, []int, error) {
+	w := NewTextWriter()
+	for _, ch := range []byte(input) {
+		c.AddInput(int(ch))
+	}
+	for {
+		status, err := c.RunUntil()
+		for v, ok := c.Output(); ok; v, ok = c.Output() {
+			w.PushInt(v)
+		}
+		if err != nil {
+			return w.String(), w.Answers, err
+		}
+		switch status {
+		case NeedsInput:
+			return w.String(), w.Answers, c.inputExhausted(nil)
+		case Halted:
+			return w.String(), w.Answers, nil
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRunText(t *testing.T) {
+	// Reads three characters and echoes them, then outputs 1000.
+	const readThree = "3,20,4,20,3,20,4,20,3,20,4,20,104,1000,99"
+	tests := []struct {
+		name      string
+		program   string
+		input     string
+		text      string
+		answers   []int
+		exhausted bool
+	}{
+		{"text", "104,72,104,105,104,10,99", "", "Hi\n", nil, false},
+		{"answer", "104,79,104,75,104,128,104,-1,99", "", "OK", []int{128, -1}, false},
+		{"echo", readThree, "abc", "abc", []int{1000}, false},
+		{"out of input", readThree, "ab", "ab", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			text, answers, err := NewComputer(ParseProgram(test.program)).RunText(test.input)
+			var exhausted *InputExhaustedError
+			if test.exhausted != errors.As(err, &exhausted) {
+				t.Errorf("got error %v, want input exhausted: %v", err, test.exhausted)
+			} else if !test.exhausted && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if text != test.text {
+				t.Errorf("got text %q, want %q", text, test.text)
+			}
+			if !reflect.DeepEqual(answers, test.answers) {
+				t.Errorf("got answers %v, want %v", answers, test.answers)
+			}
+		})
+	}
+}
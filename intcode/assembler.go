@@ -0,0 +1,217 @@
+package intcode
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Assembly syntax, one statement per line:
+//
+//	; comments start with a semicolon
+//	loop:   in [x]                  ; address mode
+//	        add [x], #5, rb[-1]     ; immediate and relative modes
+//	        jinz #1, #loop          ; labels may be used as values
+//	        halt
+//	x:      .data 0, loop+1         ; raw words
+//
+// Operand values are integers, labels or a label plus/minus an integer.
+
+var instructionsByName = func() map[string]InstructionType {
+	m := make(map[string]InstructionType)
+	for opcode, name := range instructionNames {
+		m[name] = opcode
+	}
+	return m
+}()
+
+func encodeInstruction(opcode InstructionType, modes []ParamMode) int {
+	instr := int(opcode)
+	factor := 100
+	for _, mode := range modes {
+		instr += int(mode) * factor
+		factor *= 10
+	}
+	return instr
+}
+
+var (
+	labelRE   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	valueRE   = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)?\s*([+-]?\s*\d+)?$`)
+	addressRE = regexp.MustCompile(`^\[(.*)\]$`)
+	relRE     = regexp.MustCompile(`^rb\[(.*)\]$`)
+)
+
+type value struct {
+	label  string
+	offset int
+}
+
+func parseValue(s string) (value, error) {
+	s = strings.TrimSpace(s)
+	m := valueRE.FindStringSubmatch(s)
+	if s == "" || m == nil {
+		return value{}, fmt.Errorf("Invalid value: %q", s)
+	}
+	v := value{label: m[1]}
+	if m[2] != "" {
+		offset, err := strconv.Atoi(strings.Replace(m[2], " ", "", -1))
+		if err != nil {
+			return value{}, fmt.Errorf("Invalid value: %q", s)
+		}
+		if v.label != "" && m[2][0] != '+' && m[2][0] != '-' {
+			return value{}, fmt.Errorf("Invalid value: %q", s)
+		}
+		v.offset = offset
+	}
+	return v, nil
+}
+
+func parseOperand(s string) (ParamMode, value, error) {
+	s = strings.TrimSpace(s)
+	var mode ParamMode
+	var inner string
+	if strings.HasPrefix(s, "#") {
+		mode, inner = Immediate, s[1:]
+	} else if m := relRE.FindStringSubmatch(s); m != nil {
+		mode, inner = Relative, m[1]
+	} else if m := addressRE.FindStringSubmatch(s); m != nil {
+		mode, inner = Address, m[1]
+	} else {
+		return -1, value{}, fmt.Errorf("Invalid operand: %q", s)
+	}
+	v, err := parseValue(inner)
+	return mode, v, err
+}
+
+type statement struct {
+	line    int
+	address int
+	opcode  InstructionType
+	modes   []ParamMode
+	values  []value
+	isData  bool
+}
+
+func (s statement) size() int {
+	if s.isData {
+		return len(s.values)
+	}
+	return len(s.values) + 1
+}
+
+func stripComment(line string) string {
+	if idx := strings.Index(line, ";"); idx >= 0 {
+		return line[:idx]
+	}
+	return line
+}
+
+func splitOperands(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func parseStatement(text string) (statement, error) {
+	fields := strings.Fields(text)
+	mnemonic := fields[0]
+	args := splitOperands(strings.TrimSpace(text)[len(mnemonic):])
+	if mnemonic == ".data" {
+		if len(args) == 0 {
+			return statement{}, fmt.Errorf(".data requires at least one value")
+		}
+		stmt := statement{isData: true, values: make([]value, len(args))}
+		for i, arg := range args {
+			v, err := parseValue(arg)
+			if err != nil {
+				return statement{}, err
+			}
+			stmt.values[i] = v
+		}
+		return stmt, nil
+	}
+	opcode, ok := instructionsByName[mnemonic]
+	if !ok {
+		return statement{}, fmt.Errorf("Unknown mnemonic: %q", mnemonic)
+	}
+	expectedModes := expectedParamModes[opcode]
+	if len(args) != len(expectedModes) {
+		return statement{}, fmt.Errorf("%s expects %d operands, got %d", mnemonic, len(expectedModes), len(args))
+	}
+	stmt := statement{
+		opcode: opcode,
+		modes:  make([]ParamMode, len(args)),
+		values: make([]value, len(args)),
+	}
+	for i, arg := range args {
+		mode, v, err := parseOperand(arg)
+		if err != nil {
+			return statement{}, err
+		}
+		if mode == Immediate && expectedModes[i] == Address {
+			return statement{}, fmt.Errorf("Unexpected immediate mode for param #%d of %s", i+1, mnemonic)
+		}
+		stmt.modes[i] = mode
+		stmt.values[i] = v
+	}
+	return stmt, nil
+}
+
+// Assemble translates assembly source into a program accepted by NewComputer.
+func Assemble(src string) ([]int, error) {
+	var stmts []statement
+	labels := make(map[string]int)
+	address := 0
+	for i, line := range strings.Split(src, "\n") {
+		lineNum := i + 1
+		text := strings.TrimSpace(stripComment(line))
+		for {
+			idx := strings.Index(text, ":")
+			if idx < 0 {
+				break
+			}
+			label := strings.TrimSpace(text[:idx])
+			if !labelRE.MatchString(label) {
+				return nil, fmt.Errorf("Line %d: invalid label %q", lineNum, label)
+			}
+			if _, ok := labels[label]; ok {
+				return nil, fmt.Errorf("Line %d: duplicate label %q", lineNum, label)
+			}
+			labels[label] = address
+			text = strings.TrimSpace(text[idx+1:])
+		}
+		if text == "" {
+			continue
+		}
+		stmt, err := parseStatement(text)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %v", lineNum, err)
+		}
+		stmt.line = lineNum
+		stmt.address = address
+		stmts = append(stmts, stmt)
+		address += stmt.size()
+	}
+	program := make([]int, 0, address)
+	for _, stmt := range stmts {
+		if !stmt.isData {
+			program = append(program, encodeInstruction(stmt.opcode, stmt.modes))
+		}
+		for _, v := range stmt.values {
+			x := v.offset
+			if v.label != "" {
+				addr, ok := labels[v.label]
+				if !ok {
+					return nil, fmt.Errorf("Line %d: undefined label %q", stmt.line, v.label)
+				}
+				x += addr
+			}
+			program = append(program, x)
+		}
+	}
+	return program, nil
+}
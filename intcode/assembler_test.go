@@ -0,0 +1,55 @@
+package intcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	src := `
+loop:   in [x]          ; read
+        add [x], #5, rb[-1]
+        jinz #1, #loop
+        halt
+x:      .data 0, loop+1, x-2
+`
+	want := []int{3, 10, 21001, 10, 5, -1, 1105, 1, 0, 99, 0, 1, 8}
+	got, err := Assemble(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unknown mnemonic", "halt\nfoo [1]", `Line 2: Unknown mnemonic: "foo"`},
+		{"too few operands", "add [1], [2]", "Line 1: add expects 3 operands, got 2"},
+		{"too many operands", "halt #1", "Line 1: halt expects 0 operands, got 1"},
+		{"immediate address", "in #5", "Line 1: Unexpected immediate mode for param #1 of in"},
+		{"invalid operand", "out @5", `Line 1: Invalid operand: "@5"`},
+		{"invalid value", "out [five+]", `Line 1: Invalid value: "five+"`},
+		{"empty data", ".data", "Line 1: .data requires at least one value"},
+		{"undefined label", "jinz #1, #nowhere\nhalt", `Line 1: undefined label "nowhere"`},
+		{"duplicate label", "a: halt\na: halt", `Line 2: duplicate label "a"`},
+		{"invalid label", "1a: halt", `Line 1: invalid label "1a"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Assemble(test.src)
+			if err == nil {
+				t.Fatalf("no error, want %q", test.want)
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %q, want %q", err, test.want)
+			}
+		})
+	}
+}
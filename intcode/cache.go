@@ -0,0 +1,81 @@
+package intcode
+
+// decoded is an instruction decoded from its opcode word. Computers cache
+// them by address, and invalidate an entry when its word is written to.
+// Parameters are not cached, since they are read on every execution anyway.
+// Fields are small so that caching a whole program is cheap.
+type decoded struct {
+	opcode    uint8
+	modes     [maxParams]uint8
+	numParams uint8
+	valid     bool
+}
+
+// fetch returns the decoded instruction at ptr, decoding it if it's not
+// cached. Invalid instructions are not cached.
+func (c *Computer) fetch(ptr int) (decoded, error) {
+	if ptr >= 0 && ptr < len(c.cache) && c.cache[ptr].valid {
+		return c.cache[ptr], nil
+	}
+	instr, err := c.load(ptr)
+	if err != nil {
+		return decoded{}, err
+	}
+	opcode, modes, numParams, err := decodeModes(instr)
+	if err != nil {
+		return decoded{}, c.locate(err)
+	}
+	for i, expected := range expectedModesTable[opcode] {
+		if expected == Address && modes[i] == Immediate {
+			return decoded{}, c.locate(&InvalidModeError{Instruction: instr, Param: i + 1, Mode: int(Immediate)})
+		}
+	}
+	d := decoded{opcode: uint8(opcode), numParams: uint8(numParams), valid: true}
+	for i, mode := range modes {
+		d.modes[i] = uint8(mode)
+	}
+	if c.NoCache {
+		return d, nil
+	}
+	if ptr >= len(c.cache) {
+		cache := make([]decoded, len(c.state))
+		copy(cache, c.cache)
+		c.cache = cache
+	}
+	c.cache[ptr] = d
+	return d, nil
+}
+
+// fusedJump executes the jump at the instruction pointer right after the
+// comparison at ptr, if it jumps on the flag just stored at flagAddr.
+// Otherwise it does nothing, and the next instruction is left to the next
+// step. Comparisons that store into their own words or the jump's are not
+// fused, since the jump must then be decoded again.
+func (c *Computer) fusedJump(ptr, flagAddr, flag int) error {
+	if c.MaxInstructions > 0 && c.instructionCount >= c.MaxInstructions {
+		return nil
+	}
+	jump := c.instructionPointer
+	if flagAddr >= ptr && flagAddr < jump+3 {
+		return nil
+	}
+	d, err := c.fetch(jump)
+	opcode := InstructionType(d.opcode)
+	if err != nil || (opcode != JumpIfNonZero && opcode != JumpIfZero) || ParamMode(d.modes[0]) == Immediate {
+		return nil
+	}
+	if condAddr, err := c.param(jump, 0, ParamMode(d.modes[0]), Address); err != nil || condAddr != flagAddr {
+		return nil
+	}
+	target, err := c.param(jump, 1, ParamMode(d.modes[1]), Immediate)
+	if err != nil {
+		return err
+	}
+	if (flag != 0) == (opcode == JumpIfNonZero) {
+		c.instructionPointer = target
+	} else {
+		c.instructionPointer = jump + 3
+	}
+	c.instructionCount++
+	return nil
+}
@@ -0,0 +1,69 @@
+package intcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+// configs are the ways of running a program that must give the same
+// results.
+var configs = []struct {
+	name      string
+	configure func(c *Computer)
+}{
+	{"cached", func(c *Computer) {}},
+	{"nocache", func(c *Computer) { c.NoCache, c.NoFusion = true, true }},
+	{"nofusion", func(c *Computer) { c.NoFusion = true }},
+}
+
+func TestCacheInvalidatedOnWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		program string
+		want    []int
+	}{
+		// Rewrites "4,30" as "104,30" and runs it again.
+		{"opcode", "4,30,1101,0,104,0,1001,31,1,31,1008,31,2,32,1006,32,0,99,0,0,0,0,0,0,0,0,0,0,0,0,5,0,0", []int{5, 30}},
+		// Rewrites the fused jump "1005,32,17" as "1006,32,17" after
+		// taking it once.
+		{"fused jump", "1001,31,1,31,1007,31,3,32,1005,32,17,104,2,99,0,0,0,104,1,1101,0,1006,8,1105,1,0,0,0,0,0,0,0,0", []int{1, 2}},
+		// The compare stores its flag into its own last parameter, so
+		// the jump reads address 0.
+		{"flag over compare", "1108,0,5,3,1005,0,10,104,0,99,104,1,99", []int{1}},
+		// The flag is stored over the jump's condition parameter.
+		{"flag over jump", "1108,5,5,5,1005,0,10,104,0,99,104,1,99", []int{1}},
+	}
+	for _, test := range tests {
+		for _, config := range configs {
+			t.Run(test.name+"/"+config.name, func(t *testing.T) {
+				c := NewComputer(ParseProgram(test.program))
+				config.configure(c)
+				c.MaxInstructions = 100
+				got, err := c.RunWith()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, test.want) {
+					t.Errorf("got %v, want %v", got, test.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCacheKeepsInstructionCount(t *testing.T) {
+	counts := make(map[string]int)
+	for _, config := range configs {
+		c := NewComputer(ParseProgram(day5Input))
+		config.configure(c)
+		if _, err := c.RunWith(5); err != nil {
+			t.Fatal(err)
+		}
+		counts[config.name] = c.InstructionCount()
+	}
+	for name, count := range counts {
+		if count != counts["nocache"] {
+			t.Errorf("%s executed %d instructions, want %d", name, count, counts["nocache"])
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package intcode
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type EdgeKind int
+
+const (
+	// Fallthrough goes to the next instruction.
+	Fallthrough EdgeKind = iota
+	// Jump goes to the target of a jump instruction.
+	Jump
+	// Call goes to the entry of a subroutine.
+	Call
+	// CallReturn goes from a call to where the subroutine returns.
+	CallReturn
+)
+
+var edgeKindNames = map[EdgeKind]string{
+	Fallthrough: "fallthrough",
+	Jump:        "jump",
+	Call:        "call",
+	CallReturn:  "return",
+}
+
+func (k EdgeKind) String() string {
+	return edgeKindNames[k]
+}
+
+type Edge struct {
+	To   int
+	Kind EdgeKind
+}
+
+// BasicBlock is a sequence of instructions from Start to End (exclusive) that
+// is only entered at Start and only left after its last instruction.
+type BasicBlock struct {
+	Start, End int
+	// Instructions are the addresses of the instructions in the block.
+	Instructions []int
+	Edges        []Edge
+	// Returns is true if the block ends by jumping to an address stored
+	// relative to the relative base, like a subroutine return.
+	Returns bool
+	// Indirect is true if the block ends with a jump to an unknown address.
+	Indirect bool
+	// Dynamic is true if the block contains code modified at runtime.
+	Dynamic bool
+}
+
+// CFG is the control-flow graph of the code statically reachable from
+// address 0. Only jumps to immediate addresses are followed.
+type CFG struct {
+	Blocks []*BasicBlock
+	// Subroutines are the entry addresses of called subroutines.
+	Subroutines []int
+	// Invalid are the reachable addresses that don't hold a valid
+	// instruction.
+	Invalid []int
+	program []int
+	instrs  map[int]instruction
+	code    map[int]bool
+}
+
+// isConst returns the value of the parameter if it's immediate.
+func (instr instruction) isConst(i int) (int, bool) {
+	return instr.params[i], instr.modes[i] == Immediate
+}
+
+func (instr instruction) isJump() bool {
+	return instr.opcode == JumpIfZero || instr.opcode == JumpIfNonZero
+}
+
+// branches returns whether a jump instruction may be taken and may fall
+// through, considering constant conditions.
+func (instr instruction) branches() (taken, falls bool) {
+	cond, ok := instr.isConst(0)
+	if !ok {
+		return true, true
+	}
+	jumps := (cond != 0) == (instr.opcode == JumpIfNonZero)
+	return jumps, !jumps
+}
+
+// storedConst returns the constant stored by an add or mul of immediates,
+// and the relative offset where it's stored.
+func (instr instruction) storedConst() (value, offset int, ok bool) {
+	if instr.opcode != Add && instr.opcode != Mul || instr.modes[2] != Relative {
+		return 0, 0, false
+	}
+	a, okA := instr.isConst(0)
+	b, okB := instr.isConst(1)
+	if !okA || !okB {
+		return 0, 0, false
+	}
+	if instr.opcode == Add {
+		return a + b, instr.params[2], true
+	}
+	return a * b, instr.params[2], true
+}
+
+// isCall returns whether instr is an unconditional jump to an immediate
+// address preceded by prev storing the address after the jump in the stack.
+func isCall(prev *instruction, instr instruction) bool {
+	if prev == nil || !instr.isJump() || instr.modes[1] != Immediate {
+		return false
+	}
+	if taken, falls := instr.branches(); !taken || falls {
+		return false
+	}
+	ret, _, ok := prev.storedConst()
+	return ok && ret == instr.address+instr.size()
+}
+
+// BuildCFG discovers the code reachable from address 0 and splits it into
+// basic blocks. A call is recognized as the return address being stored
+// relative to the relative base right before an unconditional jump, and a
+// return as an unconditional jump to a relative address.
+func BuildCFG(program []int) *CFG {
+	return buildCFG(program, nil)
+}
+
+// buildCFG is like BuildCFG, but also discovers code from the extra roots.
+func buildCFG(program []int, roots []int) *CFG {
+	g := &CFG{
+		program: program,
+		instrs:  make(map[int]instruction),
+		code:    make(map[int]bool),
+	}
+	leaders := map[int]bool{0: true}
+	edges := make(map[int][]Edge)
+	prev := make(map[int]int)
+	subroutines := make(map[int]bool)
+	invalid := make(map[int]bool)
+	returns := make(map[int]bool)
+	indirect := make(map[int]bool)
+
+	work := []int{0}
+	for _, root := range roots {
+		leaders[root] = true
+		work = append(work, root)
+	}
+	visit := func(from, to int, kind EdgeKind) {
+		edges[from] = append(edges[from], Edge{to, kind})
+		if kind == Fallthrough {
+			prev[to] = from
+		} else {
+			leaders[to] = true
+		}
+		work = append(work, to)
+	}
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+		if _, ok := g.instrs[addr]; ok || invalid[addr] {
+			continue
+		}
+		instr, ok := decodeAt(program, addr)
+		if !ok {
+			invalid[addr] = true
+			continue
+		}
+		g.instrs[addr] = instr
+		for i := addr; i < addr+instr.size(); i++ {
+			g.code[i] = true
+		}
+		next := addr + instr.size()
+		switch {
+		case instr.opcode == Halt:
+		case instr.isJump():
+			taken, falls := instr.branches()
+			target, isConst := instr.isConst(1)
+			var p *instruction
+			if from, ok := prev[addr]; ok {
+				pi := g.instrs[from]
+				p = &pi
+			}
+			switch {
+			case isCall(p, instr):
+				subroutines[target] = true
+				visit(addr, target, Call)
+				visit(addr, next, CallReturn)
+				continue
+			case !taken:
+			case isConst:
+				visit(addr, target, Jump)
+			case instr.modes[1] == Relative && !falls:
+				returns[addr] = true
+			default:
+				indirect[addr] = true
+			}
+			if falls {
+				visit(addr, next, Fallthrough)
+				leaders[next] = true
+			}
+		default:
+			visit(addr, next, Fallthrough)
+		}
+	}
+
+	var addrs []int
+	for addr := range g.instrs {
+		addrs = append(addrs, addr)
+	}
+	sort.Ints(addrs)
+	var block *BasicBlock
+	for i, addr := range addrs {
+		if block == nil || leaders[addr] {
+			block = &BasicBlock{Start: addr}
+			g.Blocks = append(g.Blocks, block)
+		}
+		instr := g.instrs[addr]
+		block.Instructions = append(block.Instructions, addr)
+		block.End = addr + instr.size()
+		last := instr.opcode == Halt || instr.isJump() ||
+			i+1 == len(addrs) || addrs[i+1] != block.End || leaders[addrs[i+1]]
+		if !last {
+			continue
+		}
+		block.Edges = edges[addr]
+		block.Returns = returns[addr]
+		block.Indirect = indirect[addr]
+		block = nil
+	}
+	for addr := range invalid {
+		g.Invalid = append(g.Invalid, addr)
+	}
+	sort.Ints(g.Invalid)
+	for addr := range subroutines {
+		g.Subroutines = append(g.Subroutines, addr)
+	}
+	sort.Ints(g.Subroutines)
+	return g
+}
+
+// Block returns the block starting at addr, if any.
+func (g *CFG) Block(addr int) *BasicBlock {
+	i := sort.Search(len(g.Blocks), func(i int) bool { return g.Blocks[i].Start >= addr })
+	if i < len(g.Blocks) && g.Blocks[i].Start == addr {
+		return g.Blocks[i]
+	}
+	return nil
+}
+
+// MarkDynamic flags the blocks containing any of addrs as modified at
+// runtime, e.g. with the addresses found by a SelfModDetector.
+func (g *CFG) MarkDynamic(addrs ...int) {
+	for _, addr := range addrs {
+		for _, block := range g.Blocks {
+			if addr >= block.Start && addr < block.End {
+				block.Dynamic = true
+			}
+		}
+	}
+}
+
+// IsCode returns whether addr is part of a reachable instruction.
+func (g *CFG) IsCode(addr int) bool {
+	return g.code[addr]
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+var edgeStyles = map[EdgeKind]string{
+	Fallthrough: "",
+	Jump:        ` [color=blue]`,
+	Call:        ` [style=dashed, label="call"]`,
+	CallReturn:  ` [style=dotted]`,
+}
+
+// WriteDOT writes the graph in Graphviz format, with a node per block
+// listing its instructions.
+func (g *CFG) WriteDOT(w io.Writer) error {
+	subroutines := make(map[int]bool)
+	for _, addr := range g.Subroutines {
+		subroutines[addr] = true
+	}
+	var b strings.Builder
+	b.WriteString("digraph cfg {\n")
+	b.WriteString("\tnode [shape=box, fontname=monospace];\n")
+	for _, block := range g.Blocks {
+		var label strings.Builder
+		for _, addr := range block.Instructions {
+			fmt.Fprintf(&label, "%d: %s\\l", addr, dotEscaper.Replace(g.instrs[addr].String()))
+		}
+		switch {
+		case block.Returns:
+			label.WriteString("(return)\\l")
+		case block.Indirect:
+			label.WriteString("(indirect jump)\\l")
+		}
+		attrs := ""
+		if subroutines[block.Start] {
+			attrs += ", peripheries=2"
+		}
+		if block.Dynamic {
+			attrs += ", style=filled, fillcolor=lightpink"
+		}
+		fmt.Fprintf(&b, "\t\"b%d\" [label=\"%s\"%s];\n", block.Start, label.String(), attrs)
+	}
+	for _, addr := range g.Invalid {
+		fmt.Fprintf(&b, "\t\"b%d\" [label=\"%d: (invalid)\\l\", style=dashed];\n", addr, addr)
+	}
+	for _, block := range g.Blocks {
+		for _, e := range block.Edges {
+			fmt.Fprintf(&b, "\t\"b%d\" -> \"b%d\"%s;\n", block.Start, e.To, edgeStyles[e.Kind])
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,101 @@
+package intcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type blockSummary struct {
+	Start, End   int
+	Instructions []int
+	Edges        []Edge
+	Returns      bool
+}
+
+func summarize(g *CFG) []blockSummary {
+	var blocks []blockSummary
+	for _, b := range g.Blocks {
+		blocks = append(blocks, blockSummary{b.Start, b.End, b.Instructions, b.Edges, b.Returns})
+	}
+	return blocks
+}
+
+func TestBuildCFG(t *testing.T) {
+	tests := []struct {
+		name        string
+		program     string
+		blocks      []blockSummary
+		subroutines []int
+		invalid     []int
+	}{
+		{
+			"split at jump and target",
+			"1101,1,2,20,1005,20,9,104,0,99",
+			[]blockSummary{
+				{0, 7, []int{0, 4}, []Edge{{9, Jump}, {7, Fallthrough}}, false},
+				{7, 9, []int{7}, []Edge{{9, Fallthrough}}, false},
+				{9, 10, []int{9}, nil, false},
+			},
+			nil, nil,
+		},
+		{
+			"call and return",
+			"109,100,21101,9,0,0,1105,1,10,99,104,7,2105,1,0",
+			[]blockSummary{
+				{0, 9, []int{0, 2, 6}, []Edge{{10, Call}, {9, CallReturn}}, false},
+				{9, 10, []int{9}, nil, false},
+				{10, 15, []int{10, 12}, nil, true},
+			},
+			[]int{10}, nil,
+		},
+		{
+			"invalid targets",
+			"1005,5,-5,1105,1,50,42",
+			[]blockSummary{
+				{0, 3, []int{0}, []Edge{{-5, Jump}, {3, Fallthrough}}, false},
+				{3, 6, []int{3}, []Edge{{50, Jump}}, false},
+			},
+			nil, []int{-5, 50},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := BuildCFG(ParseProgram(test.program))
+			if got := summarize(g); !reflect.DeepEqual(got, test.blocks) {
+				t.Errorf("got blocks %+v, want %+v", got, test.blocks)
+			}
+			if !reflect.DeepEqual(g.Subroutines, test.subroutines) {
+				t.Errorf("got subroutines %v, want %v", g.Subroutines, test.subroutines)
+			}
+			if !reflect.DeepEqual(g.Invalid, test.invalid) {
+				t.Errorf("got invalid %v, want %v", g.Invalid, test.invalid)
+			}
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := BuildCFG(ParseProgram("109,100,21101,9,0,0,1105,1,10,99,104,7,1105,1,-5"))
+	var b strings.Builder
+	if err := g.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"digraph cfg {\n",
+		"\t\"b10\" [label=\"10: ",
+		", peripheries=2];\n",
+		"\t\"b-5\" [label=\"-5: (invalid)\\l\", style=dashed];\n",
+		"\t\"b0\" -> \"b10\" [style=dashed, label=\"call\"];\n",
+		"\t\"b0\" -> \"b9\" [style=dotted];\n",
+		"\t\"b10\" -> \"b-5\" [color=blue];\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "\tb") || strings.Contains(got, "> b") {
+		t.Errorf("unquoted node ID in:\n%s", got)
+	}
+}
@@ -0,0 +1,158 @@
+package intcode
+
+import (
+	"io"
+	"sync"
+)
+
+// ChanReader reads inputs from a channel. Once the channel is closed and
+// drained, NextInt returns false and Err returns io.EOF, so that Run returns
+// without error.
+type ChanReader struct {
+	ch     <-chan int
+	closed bool
+}
+
+func NewChanReader(ch <-chan int) *ChanReader {
+	return &ChanReader{ch: ch}
+}
+
+func (r *ChanReader) NextInt() (int, bool) {
+	v, ok := <-r.ch
+	if !ok {
+		r.closed = true
+	}
+	return v, ok
+}
+
+func (r *ChanReader) Err() error {
+	if r.closed {
+		return io.EOF
+	}
+	return nil
+}
+
+// lastValue records the last value written to a channel adapter and copies
+// it to tee writers.
+type lastValue struct {
+	mu      sync.Mutex
+	last    int
+	hasLast bool
+	tee     []IntWriter
+}
+
+func (l *lastValue) record(i int) {
+	l.mu.Lock()
+	l.last, l.hasLast = i, true
+	l.mu.Unlock()
+	for _, w := range l.tee {
+		w.PushInt(i)
+	}
+}
+
+// Last returns the last value written, if any.
+func (l *lastValue) Last() (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last, l.hasLast
+}
+
+// ChanWriter sends outputs to a channel, copying them to tee writers. After
+// Close, values are discarded instead of sent. Close must not be called while
+// PushInt is blocked on a full channel.
+type ChanWriter struct {
+	lastValue
+	sendMu sync.Mutex
+	ch     chan<- int
+	closed bool
+}
+
+func NewChanWriter(ch chan<- int, tee ...IntWriter) *ChanWriter {
+	return &ChanWriter{ch: ch, lastValue: lastValue{tee: tee}}
+}
+
+func (w *ChanWriter) PushInt(i int) {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	if w.closed {
+		return
+	}
+	w.record(i)
+	w.ch <- i
+}
+
+// Close closes the underlying channel.
+func (w *ChanWriter) Close() {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.ch)
+	}
+}
+
+// Pipe is a buffered channel that can be used as the output of a computer
+// and the input of another. Closing a pipe is safe at any time: blocked
+// writers return, written values are discarded, and once the buffer is
+// drained NextInt returns false and Err returns io.EOF, so that Run returns
+// without error.
+type Pipe struct {
+	lastValue
+	ch        chan int
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+	eof       bool
+}
+
+func NewPipe(size int, tee ...IntWriter) *Pipe {
+	return &Pipe{
+		lastValue: lastValue{tee: tee},
+		ch:        make(chan int, size),
+		done:      make(chan struct{}),
+	}
+}
+
+func (p *Pipe) PushInt(i int) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	p.record(i)
+	select {
+	case p.ch <- i:
+	case <-p.done:
+	}
+}
+
+func (p *Pipe) NextInt() (int, bool) {
+	select {
+	case v := <-p.ch:
+		return v, true
+	case <-p.done:
+	}
+	select {
+	case v := <-p.ch:
+		return v, true
+	default:
+		p.mu.Lock()
+		p.eof = true
+		p.mu.Unlock()
+		return 0, false
+	}
+}
+
+func (p *Pipe) Close() {
+	p.closeOnce.Do(func() { close(p.done) })
+}
+
+// Err returns io.EOF after NextInt reported the pipe as closed and drained.
+func (p *Pipe) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.eof {
+		return io.EOF
+	}
+	return nil
+}
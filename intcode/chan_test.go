@@ -0,0 +1,40 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRunEndsOnClosedInput(t *testing.T) {
+	program := ParseProgram(echoLoop)
+	in := NewPipe(3)
+	in.PushInt(1)
+	in.PushInt(2)
+	in.Close()
+	var out inout
+	if err := NewComputer(program).Run(in, &out); err != nil {
+		t.Errorf("Run on closed pipe: %v", err)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(out.output, want) {
+		t.Errorf("outputs are %v, want %v", out.output, want)
+	}
+
+	ch := make(chan int, 1)
+	ch <- 3
+	close(ch)
+	if err := NewComputer(program).Run(NewChanReader(ch), &out); err != nil {
+		t.Errorf("Run on closed channel: %v", err)
+	}
+}
+
+func TestRunFailsOnExhaustedInput(t *testing.T) {
+	err := NewComputer(ParseProgram(echoLoop)).Run(&inout{input: []int{1}}, &inout{})
+	var exhausted *InputExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("got %v, want InputExhaustedError", err)
+	}
+	if exhausted.Address != 0 {
+		t.Errorf("exhausted @ %d, want 0", exhausted.Address)
+	}
+}
@@ -1,7 +1,10 @@
 package intcode
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"math"
 )
 
 type InstructionType int
@@ -54,53 +57,91 @@ var expectedParamModes = map[InstructionType][]ParamMode{
 	Halt:          []ParamMode{},
 }
 
-func decodeOpcode(i int) (InstructionType, error) {
+const maxParams = 3
+
+var validOpcodes, expectedModesTable = func() (valid [100]bool, modes [100][]ParamMode) {
 	for _, instr := range instructionTypes {
-		if int(instr) == i {
-			return instr, nil
-		}
+		valid[instr] = true
+		modes[instr] = expectedParamModes[instr]
 	}
-	return -1, fmt.Errorf("Unknown opcode: %d", i)
-}
+	return
+}()
 
-func decodeMode(i int) (ParamMode, error) {
-	if i < 0 || i > 2 {
-		return -1, fmt.Errorf("Unknown param mode: %d", i)
+func decodeOpcode(instr int) (InstructionType, error) {
+	i := instr % 100
+	if i < 0 || i >= len(validOpcodes) || !validOpcodes[i] {
+		return -1, &InvalidOpcodeError{Instruction: instr}
 	}
-	return ParamMode(i), nil
+	return InstructionType(i), nil
 }
 
-func decodeInstruction(instr int) (InstructionType, []ParamMode, error) {
-	opcode, err := decodeOpcode(instr % 100)
+// decodeModes is the allocation-free version of decodeInstruction, returning
+// the modes in an array along with the number of params.
+func decodeModes(instr int) (InstructionType, [maxParams]ParamMode, int, error) {
+	var modes [maxParams]ParamMode
+	opcode, err := decodeOpcode(instr)
 	if err != nil {
-		return -1, nil, err
+		return -1, modes, 0, err
 	}
 	modeMask := instr / 100
-	numParams := len(expectedParamModes[opcode])
-	modes := make([]ParamMode, numParams)
+	numParams := len(expectedModesTable[opcode])
 	for i := 0; i < numParams; i++ {
-		mode, err := decodeMode(modeMask % 10)
-		if err != nil {
-			return opcode, nil, fmt.Errorf("Invalid mode at position %d: %v", i, err)
+		mode := modeMask % 10
+		if mode < 0 || mode > int(Relative) {
+			return opcode, modes, 0, &InvalidModeError{Instruction: instr, Param: i + 1, Mode: mode}
 		}
-		modes[i] = mode
+		modes[i] = ParamMode(mode)
 		modeMask /= 10
 	}
-	return opcode, modes, nil
+	return opcode, modes, numParams, nil
+}
+
+func decodeInstruction(instr int) (InstructionType, []ParamMode, error) {
+	opcode, modes, numParams, err := decodeModes(instr)
+	if err != nil {
+		return opcode, nil, err
+	}
+	return opcode, append([]ParamMode(nil), modes[:numParams]...), nil
 }
 
+// DefaultMaxMemory is the number of words a computer may address when
+// MaxMemory is not set.
+const DefaultMaxMemory = 1 << 24
+
 type Computer struct {
-	state              map[int]int
+	state              []int
 	instructionPointer int
 	relativeBase       int
-	Debug              bool
+	input              []int
+	output             []int
+	instructionCount   int
+	event              *TraceEvent
+	cache              []decoded
+	recording          bool
+	undoLog            []UndoRecord
+	undo               *UndoRecord
+	// Debug prints every executed instruction to stdout.
+	Debug bool
+	// Tracer receives an event for every executed instruction, if set.
+	Tracer Tracer
+	// MaxInstructions limits how many instructions may be executed, if positive.
+	MaxInstructions int
+	// MaxMemory limits how many words of memory may be used, if positive.
+	MaxMemory int
+	// CheckOverflow makes add and mul return an OverflowError instead of
+	// wrapping around.
+	CheckOverflow bool
+	// NoCache decodes instructions every time they are executed, instead of
+	// caching them by address.
+	NoCache bool
+	// NoFusion executes each instruction in its own step, instead of fusing
+	// a comparison with the jump that follows on its result.
+	NoFusion bool
 }
 
 func NewComputer(program []int) *Computer {
-	state := make(map[int]int)
-	for i, instruction := range program {
-		state[i] = instruction
-	}
+	state := make([]int, len(program))
+	copy(state, program)
 	return &Computer{
 		state:              state,
 		instructionPointer: 0,
@@ -109,8 +150,6 @@ func NewComputer(program []int) *Computer {
 	}
 }
 
-var halted = fmt.Errorf("Halted")
-
 type IntReader interface {
 	NextInt() (int, bool)
 }
@@ -119,104 +158,438 @@ type IntWriter interface {
 	PushInt(i int)
 }
 
+func (c *Computer) Registers() Registers {
+	return Registers{
+		InstructionPointer: c.instructionPointer,
+		RelativeBase:       c.relativeBase,
+	}
+}
+
+func (c *Computer) currentInstruction() int {
+	ptr := c.instructionPointer
+	if ptr < 0 || ptr >= len(c.state) {
+		return 0
+	}
+	return c.state[ptr]
+}
+
+// locate fills in the location of errors returned by decoding functions.
+func (c *Computer) locate(err error) error {
+	switch e := err.(type) {
+	case *InvalidOpcodeError:
+		e.Address, e.Registers = c.instructionPointer, c.Registers()
+	case *InvalidModeError:
+		e.Address, e.Registers = c.instructionPointer, c.Registers()
+	}
+	return err
+}
+
+// NewCheckedComputer returns a computer that fails with an OverflowError when
+// add or mul results don't fit in an int, instead of silently wrapping.
+func NewCheckedComputer(program []int) *Computer {
+	c := NewComputer(program)
+	c.CheckOverflow = true
+	return c
+}
+
+func addOverflows(a, b, sum int) bool {
+	return (a > 0 && b > 0 && sum < 0) || (a < 0 && b < 0 && sum >= 0)
+}
+
+func mulOverflows(a, b, product int) bool {
+	if a == 0 || b == 0 {
+		return false
+	}
+	return product/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt)
+}
+
+func (c *Computer) overflow(opcode InstructionType, a, b int) error {
+	return &OverflowError{
+		Address:     c.instructionPointer,
+		Instruction: c.currentInstruction(),
+		Opcode:      opcode,
+		A:           a,
+		B:           b,
+		Registers:   c.Registers(),
+	}
+}
+
+// InstructionCount returns the number of instructions executed so far.
+func (c *Computer) InstructionCount() int {
+	return c.instructionCount
+}
+
+func (c *Computer) maxMemory() int {
+	if c.MaxMemory > 0 {
+		return c.MaxMemory
+	}
+	return DefaultMaxMemory
+}
+
+func (c *Computer) checkAddress(addr int) error {
+	if addr < 0 {
+		return &InvalidAddressError{
+			Address:     c.instructionPointer,
+			Instruction: c.currentInstruction(),
+			Target:      addr,
+			Registers:   c.Registers(),
+		}
+	}
+	if limit := c.maxMemory(); addr >= limit {
+		return &MemoryLimitError{
+			Address:     c.instructionPointer,
+			Instruction: c.currentInstruction(),
+			Target:      addr,
+			Limit:       limit,
+			Registers:   c.Registers(),
+		}
+	}
+	return nil
+}
+
+// readerErr returns the error of an exhausted reader, if it has an Err method.
+func readerErr(in IntReader) error {
+	if r, ok := in.(interface{ Err() error }); ok {
+		return r.Err()
+	}
+	return nil
+}
+
+func (c *Computer) inputExhausted(in IntReader) error {
+	return &InputExhaustedError{
+		Address:     c.instructionPointer,
+		Instruction: c.currentInstruction(),
+		Registers:   c.Registers(),
+		Err:         readerErr(in),
+	}
+}
+
+func (c *Computer) load(addr int) (int, error) {
+	if addr >= 0 && addr < len(c.state) {
+		return c.state[addr], nil
+	}
+	if err := c.checkAddress(addr); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+func (c *Computer) store(addr, value int) error {
+	if addr < 0 || addr >= len(c.state) {
+		if err := c.checkAddress(addr); err != nil {
+			return err
+		}
+		c.grow(addr + 1)
+	}
+	if c.event != nil {
+		c.event.Writes = append(c.event.Writes, MemoryWrite{addr, c.state[addr], value})
+	}
+	if c.undo != nil {
+		c.undo.Writes = append(c.undo.Writes, MemoryWrite{addr, c.state[addr], value})
+	}
+	c.state[addr] = value
+	if addr < len(c.cache) {
+		c.cache[addr].valid = false
+	}
+	return nil
+}
+
+func (c *Computer) grow(size int) {
+	if size <= cap(c.state) {
+		c.state = c.state[:size]
+		return
+	}
+	newCap := 2 * cap(c.state)
+	if newCap < size {
+		newCap = size
+	}
+	if limit := c.maxMemory(); newCap > limit {
+		newCap = limit
+	}
+	state := make([]int, size, newCap)
+	copy(state, c.state)
+	c.state = state
+}
+
 func (c *Computer) debugInstructions(numParams int) []int {
 	ptr := c.instructionPointer
 	rawParams := make([]int, numParams+1)
-	rawParams[0] = c.state[ptr]
-	for i := 1; i < numParams+1; i++ {
-		rawParams[i] = c.state[ptr+i]
+	for i := range rawParams {
+		rawParams[i], _ = c.load(ptr + i)
 	}
 	return rawParams
 }
 
-func (c *Computer) step(in IntReader, out IntWriter) error {
+// param returns the value of the i-th parameter of the instruction at ptr,
+// or its address if expected is Address.
+func (c *Computer) param(ptr, i int, mode, expected ParamMode) (int, error) {
+	value, err := c.load(ptr + 1 + i)
+	if err != nil {
+		return 0, err
+	}
+	switch mode {
+	case Address:
+		if expected == Immediate {
+			return c.load(value)
+		}
+	case Relative:
+		if expected == Address {
+			return value + c.relativeBase, nil
+		}
+		return c.load(value + c.relativeBase)
+	}
+	return value, nil
+}
+
+// step executes the instruction at the instruction pointer. If fuse is true,
+// a comparison may be executed together with the jump that follows it.
+func (c *Computer) step(in IntReader, out IntWriter, fuse bool) error {
 	ptr := c.instructionPointer
-	opcode, modes, err := decodeInstruction(c.state[ptr])
+	if c.MaxInstructions > 0 && c.instructionCount >= c.MaxInstructions {
+		return &InstructionLimitError{
+			Address:   ptr,
+			Limit:     c.MaxInstructions,
+			Registers: c.Registers(),
+		}
+	}
+	d, err := c.fetch(ptr)
 	if err != nil {
 		return err
 	}
-	numParams := len(modes)
-	expectedModes := expectedParamModes[opcode]
-	params := make([]int, numParams)
-	for i, mode := range modes {
-		value := c.state[ptr+1+i]
-		expectedMode := expectedModes[i]
-		switch mode {
-		case Address:
-			if expectedMode == Immediate {
-				value = c.state[value]
-			}
-		case Relative:
-			switch expectedMode {
-			case Address:
-				value += c.relativeBase
-			case Immediate:
-				value = c.state[value+c.relativeBase]
-			}
-		case Immediate:
-			if expectedMode == Address {
-				return fmt.Errorf("Unexpected immediate mode for param #%d @ %d (%s %v)", i+1, ptr, instructionNames[opcode], c.debugInstructions(numParams))
-			}
+	opcode, numParams := InstructionType(d.opcode), int(d.numParams)
+	expectedModes := expectedModesTable[opcode]
+	var params [maxParams]int
+	for i := 0; i < numParams; i++ {
+		if params[i], err = c.param(ptr, i, ParamMode(d.modes[i]), expectedModes[i]); err != nil {
+			return err
 		}
-		params[i] = value
 	}
-	if c.Debug {
-		fmt.Printf("@%d: %s %v\t(%v/%d)\n", ptr, instructionNames[opcode], params, c.debugInstructions(numParams), c.relativeBase)
+	var event *TraceEvent
+	tracer := c.tracer()
+	if tracer != nil {
+		event = c.newTraceEvent(opcode, numParams, params[:numParams])
+		c.event = event
+		defer func() { c.event = nil }()
 	}
+	if c.recording {
+		c.undo = &UndoRecord{
+			Count:              c.instructionCount,
+			InstructionPointer: ptr,
+			RelativeBase:       c.relativeBase,
+		}
+		defer func() { c.undo = nil }()
+	}
+	next := ptr + numParams + 1
+	flag := 0
 	switch opcode {
 	case Add:
-		c.state[params[2]] = params[0] + params[1]
+		sum := params[0] + params[1]
+		if c.CheckOverflow && addOverflows(params[0], params[1], sum) {
+			return c.overflow(opcode, params[0], params[1])
+		}
+		err = c.store(params[2], sum)
 	case Mul:
-		c.state[params[2]] = params[0] * params[1]
+		product := params[0] * params[1]
+		if c.CheckOverflow && mulOverflows(params[0], params[1], product) {
+			return c.overflow(opcode, params[0], params[1])
+		}
+		err = c.store(params[2], product)
 	case Input:
 		v, ok := in.NextInt()
 		if !ok {
-			return fmt.Errorf("Input exhausted @ %d", ptr)
+			return c.inputExhausted(in)
+		}
+		if event != nil {
+			input := v
+			event.Input = &input
 		}
-		c.state[params[0]] = v
+		if c.undo != nil {
+			c.undo.Input = &v
+		}
+		err = c.store(params[0], v)
 	case Output:
+		if event != nil {
+			output := params[0]
+			event.Output = &output
+		}
+		if c.undo != nil {
+			output := params[0]
+			c.undo.Output = &output
+		}
 		out.PushInt(params[0])
 	case JumpIfNonZero:
 		if params[0] != 0 {
-			c.instructionPointer = params[1]
-			return nil
+			next = params[1]
 		}
 	case JumpIfZero:
 		if params[0] == 0 {
-			c.instructionPointer = params[1]
-			return nil
+			next = params[1]
 		}
 	case LessThan:
-		value := 0
 		if params[0] < params[1] {
-			value = 1
+			flag = 1
 		}
-		c.state[params[2]] = value
+		err = c.store(params[2], flag)
 	case Equals:
-		value := 0
 		if params[0] == params[1] {
-			value = 1
+			flag = 1
 		}
-		c.state[params[2]] = value
+		err = c.store(params[2], flag)
 	case OffsetRelBase:
 		c.relativeBase += params[0]
 	case Halt:
-		return halted
+		next = ptr
+	}
+	if err != nil {
+		return err
+	}
+	if event != nil {
+		event.Next = next
+		tracer.Trace(event)
+	}
+	if c.undo != nil && opcode != Halt {
+		c.undoLog = append(c.undoLog, *c.undo)
+	}
+	if opcode == Halt {
+		return ErrHalted
+	}
+	c.instructionPointer = next
+	c.instructionCount++
+	if fuse && tracer == nil && !c.recording && (opcode == LessThan || opcode == Equals) {
+		return c.fusedJump(ptr, params[2], flag)
 	}
-	c.instructionPointer += numParams + 1
 	return nil
 }
 
+type Status int
+
+const (
+	NeedsInput Status = iota
+	HasOutput
+	Halted
+)
+
+var statusNames = map[Status]string{
+	NeedsInput: "needs input",
+	HasOutput:  "has output",
+	Halted:     "halted",
+}
+
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// AddInput queues values to be consumed by input instructions in RunUntil.
+func (c *Computer) AddInput(values ...int) {
+	c.input = append(c.input, values...)
+}
+
+// Output removes and returns the oldest value produced in RunUntil.
+func (c *Computer) Output() (int, bool) {
+	if len(c.output) == 0 {
+		return 0, false
+	}
+	v := c.output[0]
+	c.output = c.output[1:]
+	return v, true
+}
+
+type queues struct {
+	c        *Computer
+	starved  bool
+	produced bool
+}
+
+func (q *queues) NextInt() (int, bool) {
+	if len(q.c.input) == 0 {
+		q.starved = true
+		return 0, false
+	}
+	v := q.c.input[0]
+	q.c.input = q.c.input[1:]
+	return v, true
+}
+
+func (q *queues) PushInt(i int) {
+	q.c.output = append(q.c.output, i)
+	q.produced = true
+}
+
+// RunUntil executes the program until it requires an input that wasn't
+// provided with AddInput, produces an output or halts. When the status is
+// NeedsInput the input instruction is not executed, so that calling RunUntil
+// again after adding input will resume from it.
+func (c *Computer) RunUntil() (Status, error) {
+	return c.RunUntilContext(context.Background())
+}
+
+// cancelCheckInterval is the number of instructions executed between checks
+// for context cancellation.
+const cancelCheckInterval = 1024
+
+// RunUntilContext is like RunUntil, but stops with an error wrapping the
+// context's error when it's canceled or its deadline expires.
+func (c *Computer) RunUntilContext(ctx context.Context) (Status, error) {
+	q := &queues{c: c}
+	done := ctx.Done()
+	for i := 0; ; i++ {
+		if done != nil && i%cancelCheckInterval == 0 {
+			select {
+			case <-done:
+				return Halted, fmt.Errorf("Interrupted @ %d (%v): %w", c.instructionPointer, c.Registers(), ctx.Err())
+			default:
+			}
+		}
+		err := c.step(q, q, !c.NoFusion)
+		if q.starved {
+			return NeedsInput, nil
+		}
+		if err == ErrHalted {
+			return Halted, nil
+		}
+		if err != nil {
+			return Halted, err
+		}
+		if q.produced {
+			return HasOutput, nil
+		}
+	}
+}
+
+// Run executes the program, reading inputs from in and writing outputs to
+// out, until it halts. If in runs out of values, Run stops with an
+// InputExhaustedError, unless in reports io.EOF from an Err method, like a
+// closed Pipe or ChanReader: then the input was ended on purpose, and Run
+// returns nil leaving the computer waiting for input.
 func (c *Computer) Run(in IntReader, out IntWriter) error {
+	return c.RunContext(context.Background(), in, out)
+}
+
+// RunContext is like Run, but stops when ctx is done. Cancellation is only
+// noticed between instructions, so a reader that blocks forever in NextInt
+// will still block RunContext.
+func (c *Computer) RunContext(ctx context.Context, in IntReader, out IntWriter) error {
 	for {
-		err := c.step(in, out)
-		if err == nil {
-			continue
+		status, err := c.RunUntilContext(ctx)
+		if err != nil {
+			return err
 		}
-		if err == halted {
+		switch status {
+		case NeedsInput:
+			v, ok := in.NextInt()
+			if !ok {
+				if readerErr(in) == io.EOF {
+					return nil
+				}
+				return c.inputExhausted(in)
+			}
+			c.AddInput(v)
+		case HasOutput:
+			v, _ := c.Output()
+			out.PushInt(v)
+		case Halted:
 			return nil
 		}
-		return err
 	}
 }
 
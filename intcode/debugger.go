@@ -0,0 +1,284 @@
+package intcode
+
+import (
+	"fmt"
+)
+
+type Registers struct {
+	InstructionPointer int
+	RelativeBase       int
+}
+
+func (r Registers) String() string {
+	return fmt.Sprintf("ip=%d rb=%d", r.InstructionPointer, r.RelativeBase)
+}
+
+type StopReason int
+
+const (
+	StopStep StopReason = iota
+	StopBreakpoint
+	StopWatchpoint
+	StopHalt
+	// StopStart is returned when running backwards reaches the start of the
+	// recording.
+	StopStart
+)
+
+var stopReasonNames = map[StopReason]string{
+	StopStep:       "step",
+	StopBreakpoint: "breakpoint",
+	StopWatchpoint: "watchpoint",
+	StopHalt:       "halt",
+	StopStart:      "start of recording",
+}
+
+func (r StopReason) String() string {
+	return stopReasonNames[r]
+}
+
+// Stop describes why the debugger returned control. For watchpoints, Watched
+// is the address that changed from Old to New.
+type Stop struct {
+	Reason   StopReason
+	Address  int
+	Watched  int
+	Old, New int
+}
+
+func (s Stop) String() string {
+	switch s.Reason {
+	case StopWatchpoint:
+		return fmt.Sprintf("%v @ %d: [%d] %d -> %d", s.Reason, s.Address, s.Watched, s.Old, s.New)
+	default:
+		return fmt.Sprintf("%v @ %d", s.Reason, s.Address)
+	}
+}
+
+// Debugger executes a Computer one instruction at a time, stopping at
+// breakpoints and watchpoints. The same reader and writer can be used to
+// resume Computer.Run after detaching.
+//
+// If the computer is recording, the debugger can also run backwards. Inputs
+// consumed by undone instructions are fed again before reading from the
+// reader, and their outputs are not written again when re-executed, so that
+// running forward again is deterministic.
+type Debugger struct {
+	c                 *Computer
+	in                IntReader
+	out               IntWriter
+	breakpoints       map[int]bool
+	opcodeBreakpoints map[InstructionType]bool
+	watchpoints       map[int]bool
+	// replay are the inputs of undone instructions, to be consumed again.
+	replay []int
+	// skip is the number of outputs of undone instructions, which are not
+	// written again.
+	skip int
+}
+
+func NewDebugger(c *Computer, in IntReader, out IntWriter) *Debugger {
+	return &Debugger{
+		c:                 c,
+		in:                in,
+		out:               out,
+		breakpoints:       make(map[int]bool),
+		opcodeBreakpoints: make(map[InstructionType]bool),
+		watchpoints:       make(map[int]bool),
+	}
+}
+
+func (d *Debugger) SetBreakpoint(addr int)   { d.breakpoints[addr] = true }
+func (d *Debugger) ClearBreakpoint(addr int) { delete(d.breakpoints, addr) }
+
+func (d *Debugger) SetOpcodeBreakpoint(opcode InstructionType)   { d.opcodeBreakpoints[opcode] = true }
+func (d *Debugger) ClearOpcodeBreakpoint(opcode InstructionType) { delete(d.opcodeBreakpoints, opcode) }
+
+func (d *Debugger) SetWatchpoint(addr int)   { d.watchpoints[addr] = true }
+func (d *Debugger) ClearWatchpoint(addr int) { delete(d.watchpoints, addr) }
+
+func (d *Debugger) Registers() Registers {
+	return d.c.Registers()
+}
+
+// SetRegisters changes the computer registers. Since execution may diverge
+// from what was undone, outputs are written again from now on.
+func (d *Debugger) SetRegisters(r Registers) {
+	d.skip = 0
+	d.c.instructionPointer = r.InstructionPointer
+	d.c.relativeBase = r.RelativeBase
+}
+
+// Peek returns the value at addr, or 0 if it's not a valid address.
+func (d *Debugger) Peek(addr int) int {
+	v, _ := d.c.load(addr)
+	return v
+}
+
+// Poke writes value at addr. Like SetRegisters, it makes outputs be written
+// again.
+func (d *Debugger) Poke(addr, value int) error {
+	d.skip = 0
+	return d.c.store(addr, value)
+}
+
+// debuggerInput reads the inputs to replay before the debugger's reader.
+type debuggerInput struct {
+	d *Debugger
+}
+
+func (in debuggerInput) NextInt() (int, bool) {
+	if len(in.d.replay) > 0 {
+		v := in.d.replay[0]
+		in.d.replay = in.d.replay[1:]
+		return v, true
+	}
+	return in.d.in.NextInt()
+}
+
+func (in debuggerInput) Err() error {
+	if r, ok := in.d.in.(interface{ Err() error }); ok {
+		return r.Err()
+	}
+	return nil
+}
+
+// debuggerOutput drops the outputs already written before being undone.
+type debuggerOutput struct {
+	d *Debugger
+}
+
+func (out debuggerOutput) PushInt(v int) {
+	if out.d.skip > 0 {
+		out.d.skip--
+		return
+	}
+	out.d.out.PushInt(v)
+}
+
+// Instruction returns the instruction at addr and its size in words. If the
+// word at addr is not a valid instruction it is returned as .data.
+func (d *Debugger) Instruction(addr int) (string, int) {
+	window := make([]int, 4)
+	for i := range window {
+		window[i] = d.Peek(addr + i)
+	}
+	instr, ok := decodeAt(window, 0)
+	if !ok {
+		return fmt.Sprintf(".data %d", window[0]), 1
+	}
+	return instr.String(), instr.size()
+}
+
+func (d *Debugger) atBreakpoint() bool {
+	ptr := d.c.instructionPointer
+	if d.breakpoints[ptr] {
+		return true
+	}
+	opcode, err := decodeOpcode(d.Peek(ptr))
+	return err == nil && d.opcodeBreakpoints[opcode]
+}
+
+// Step executes a single instruction, regardless of breakpoints.
+func (d *Debugger) Step() (Stop, error) {
+	ptr := d.c.instructionPointer
+	before := make(map[int]int, len(d.watchpoints))
+	for addr := range d.watchpoints {
+		before[addr] = d.Peek(addr)
+	}
+	err := d.c.step(debuggerInput{d}, debuggerOutput{d}, false)
+	if err == ErrHalted {
+		return Stop{Reason: StopHalt, Address: ptr}, nil
+	}
+	if err != nil {
+		return Stop{Address: ptr}, err
+	}
+	for addr, old := range before {
+		if v := d.Peek(addr); v != old {
+			return Stop{Reason: StopWatchpoint, Address: ptr, Watched: addr, Old: old, New: v}, nil
+		}
+	}
+	return Stop{Reason: StopStep, Address: ptr}, nil
+}
+
+// Continue executes instructions until the program halts, fails, changes a
+// watched address or reaches a breakpoint. The instruction at the current
+// position is always executed, so that Continue can move past a breakpoint.
+func (d *Debugger) Continue() (Stop, error) {
+	for {
+		stop, err := d.Step()
+		if err != nil || stop.Reason != StopStep {
+			return stop, err
+		}
+		if d.atBreakpoint() {
+			return Stop{Reason: StopBreakpoint, Address: d.c.instructionPointer}, nil
+		}
+	}
+}
+
+// Detach resumes normal execution of the computer with the debugger's reader
+// and writer.
+func (d *Debugger) Detach() error {
+	return d.c.Run(debuggerInput{d}, debuggerOutput{d})
+}
+
+// StepBack reverts the last executed instruction. It requires the computer
+// to be recording since before that instruction.
+func (d *Debugger) StepBack() (Stop, error) {
+	before := make(map[int]int, len(d.watchpoints))
+	for addr := range d.watchpoints {
+		before[addr] = d.Peek(addr)
+	}
+	r, ok := d.c.Undo()
+	if !ok {
+		if !d.c.Recording() {
+			return Stop{Address: d.c.instructionPointer}, fmt.Errorf("Not recording, can't step back")
+		}
+		return Stop{Reason: StopStart, Address: d.c.instructionPointer}, nil
+	}
+	if r.Input != nil {
+		d.replay = append([]int{*r.Input}, d.replay...)
+	}
+	if r.Output != nil {
+		d.skip++
+	}
+	for addr, old := range before {
+		if v := d.Peek(addr); v != old {
+			return Stop{Reason: StopWatchpoint, Address: r.InstructionPointer, Watched: addr, Old: old, New: v}, nil
+		}
+	}
+	return Stop{Reason: StopStep, Address: r.InstructionPointer}, nil
+}
+
+// ContinueBack reverts instructions until reaching a breakpoint, a change of
+// a watched address or the start of the recording. Like Continue, it always
+// reverts at least one instruction.
+func (d *Debugger) ContinueBack() (Stop, error) {
+	for {
+		stop, err := d.StepBack()
+		if err != nil || stop.Reason != StopStep {
+			return stop, err
+		}
+		if d.atBreakpoint() {
+			return Stop{Reason: StopBreakpoint, Address: d.c.instructionPointer}, nil
+		}
+	}
+}
+
+// RunTo steps forward or backwards until count instructions were executed,
+// ignoring breakpoints and watchpoints.
+func (d *Debugger) RunTo(count int) (Stop, error) {
+	stop := Stop{Reason: StopStep, Address: d.c.instructionPointer}
+	var err error
+	for d.c.instructionCount != count && err == nil && stop.Reason == StopStep {
+		if count < d.c.instructionCount {
+			stop, err = d.StepBack()
+		} else {
+			stop, err = d.Step()
+		}
+		if stop.Reason == StopWatchpoint {
+			stop.Reason = StopStep
+		}
+	}
+	return stop, err
+}
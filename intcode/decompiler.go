@@ -0,0 +1,768 @@
+package intcode
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Decompile writes Go-like pseudo-code for the code reachable in program.
+//
+// Each subroutine found by BuildCFG becomes a function. Subroutines are
+// assumed to follow the relative base convention: the caller stores the
+// return address at rb[0] and arguments at rb[1], rb[2]..., and the callee
+// moves the relative base forward to make room for its locals, restoring it
+// before returning. Frame cells are named after their offset from the
+// relative base at the function's entry: ret, arg1, arg2... for the return
+// address and arguments, local3, local4... above them and frame[-1],
+// frame[-2]... below, in the caller's frame. Memory cells are named g<addr>,
+// or code[addr] if they're part of an instruction. In main the relative base
+// starts at 0, so its relative cells are named as memory cells.
+//
+// Conditional jumps are structured into for loops and ifs when they nest
+// properly, and otherwise printed as gotos.
+func Decompile(w io.Writer, program []int) error {
+	d := newDecompiler(BuildCFG(program))
+	var b strings.Builder
+	for i, entry := range d.entries() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		d.decompileFunction(&b, entry)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type decompiler struct {
+	g      *CFG
+	blocks map[int]*BasicBlock
+	// flags are memory cells only used to hold a comparison result that
+	// is read by the jump right after it.
+	flags map[int]bool
+	// numArgs is the number of arguments of each subroutine.
+	numArgs map[int]int
+	// dynamic are the addresses of code written to by the program.
+	dynamic map[int]bool
+}
+
+func newDecompiler(g *CFG) *decompiler {
+	d := &decompiler{
+		g:       g,
+		blocks:  make(map[int]*BasicBlock),
+		flags:   make(map[int]bool),
+		numArgs: make(map[int]int),
+		dynamic: make(map[int]bool),
+	}
+	for _, block := range g.Blocks {
+		d.blocks[block.Start] = block
+		for _, addr := range block.Instructions {
+			instr := g.instrs[addr]
+			if j := writeParam(instr); j >= 0 && instr.modes[j] == Address && g.IsCode(instr.params[j]) {
+				d.dynamic[instr.params[j]] = true
+			}
+		}
+	}
+	d.findFlags()
+	d.findArgs()
+	return d
+}
+
+func (d *decompiler) entries() []int {
+	entries := []int{0}
+	for _, addr := range d.g.Subroutines {
+		if addr != 0 {
+			entries = append(entries, addr)
+		}
+	}
+	return entries
+}
+
+// writeParam returns the index of the parameter written by instr, or -1.
+func writeParam(instr instruction) int {
+	switch instr.opcode {
+	case Add, Mul, LessThan, Equals:
+		return 2
+	case Input:
+		return 0
+	}
+	return -1
+}
+
+func isCompare(instr instruction) bool {
+	return instr.opcode == LessThan || instr.opcode == Equals
+}
+
+func (d *decompiler) findFlags() {
+	fused := make(map[int]bool)
+	for _, block := range d.g.Blocks {
+		for i, addr := range block.Instructions {
+			instr := d.g.instrs[addr]
+			for j, mode := range instr.modes {
+				if mode != Address || j == writeParam(instr) {
+					continue
+				}
+				cell := instr.params[j]
+				if instr.isJump() && j == 0 && i > 0 {
+					prev := d.g.instrs[block.Instructions[i-1]]
+					if isCompare(prev) && prev.modes[2] == Address && prev.params[2] == cell {
+						fused[cell] = true
+						continue
+					}
+				}
+				d.flags[cell] = false
+			}
+		}
+	}
+	for cell := range fused {
+		if _, ok := d.flags[cell]; !ok {
+			d.flags[cell] = true
+		}
+	}
+}
+
+// callArgs returns the instructions of a call block storing arguments,
+// indexed by their slot in the callee's frame.
+func (d *decompiler) callArgs(block *BasicBlock) map[int]int {
+	args := make(map[int]int)
+	n := len(block.Instructions)
+	for _, addr := range block.Instructions[:n-1] {
+		instr := d.g.instrs[addr]
+		j := writeParam(instr)
+		if j >= 0 && instr.modes[j] == Relative && instr.params[j] > 0 {
+			args[instr.params[j]] = addr
+		}
+	}
+	for slot := range args {
+		// Only a contiguous sequence of slots from 1 are arguments.
+		for s := 1; s < slot; s++ {
+			if _, ok := args[s]; !ok {
+				delete(args, slot)
+				break
+			}
+		}
+	}
+	return args
+}
+
+func callTarget(block *BasicBlock) (int, bool) {
+	for _, e := range block.Edges {
+		if e.Kind == Call {
+			return e.To, true
+		}
+	}
+	return 0, false
+}
+
+func (d *decompiler) findArgs() {
+	for _, block := range d.g.Blocks {
+		if target, ok := callTarget(block); ok {
+			if n := len(d.callArgs(block)); n > d.numArgs[target] {
+				d.numArgs[target] = n
+			}
+		}
+	}
+}
+
+type function struct {
+	entry  int
+	sub    bool
+	starts []int
+	blocks map[int]*BasicBlock
+	// delta is the relative base at the start of each block, relative to
+	// the one at the entry, if known.
+	delta   map[int]int
+	numArgs int
+}
+
+func (d *decompiler) newFunction(entry int) *function {
+	f := &function{
+		entry:   entry,
+		sub:     entry != 0,
+		blocks:  make(map[int]*BasicBlock),
+		delta:   map[int]int{entry: 0},
+		numArgs: d.numArgs[entry],
+	}
+	// unknown are the blocks reached with an unknown relative base.
+	unknown := make(map[int]bool)
+	work := []int{entry}
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+		block, ok := d.blocks[addr]
+		if !ok || f.blocks[addr] != nil {
+			continue
+		}
+		f.blocks[addr] = block
+		f.starts = append(f.starts, addr)
+		delta, known := f.delta[addr]
+		for _, instrAddr := range block.Instructions {
+			instr := d.g.instrs[instrAddr]
+			if instr.opcode != OffsetRelBase {
+				continue
+			}
+			if d.constantOffset(instr) {
+				delta += instr.params[0]
+			} else {
+				known = false
+			}
+		}
+		for _, e := range block.Edges {
+			if e.Kind == Call {
+				continue
+			}
+			if !known {
+				unknown[e.To] = true
+				delete(f.delta, e.To)
+			} else if _, ok := f.delta[e.To]; !ok && !unknown[e.To] {
+				f.delta[e.To] = delta
+			}
+			work = append(work, e.To)
+		}
+	}
+	sort.Ints(f.starts)
+	return f
+}
+
+// constantOffset returns whether instr moves the relative base by a constant.
+func (d *decompiler) constantOffset(instr instruction) bool {
+	return instr.modes[0] == Immediate && !d.dynamic[instr.address+1]
+}
+
+// nextBlock returns the first block of f starting at or after addr.
+func (f *function) nextBlock(addr int) (int, bool) {
+	i := sort.SearchInts(f.starts, addr)
+	if i == len(f.starts) {
+		return 0, false
+	}
+	return f.starts[i], true
+}
+
+func (f *function) name() string {
+	if !f.sub {
+		return "main"
+	}
+	return fmt.Sprintf("f%d", f.entry)
+}
+
+func (f *function) slotName(slot int) string {
+	switch {
+	case slot < 0:
+		return fmt.Sprintf("frame[%d]", slot)
+	case slot == 0 && f.sub:
+		return "ret"
+	case slot <= f.numArgs:
+		return fmt.Sprintf("arg%d", slot)
+	}
+	return fmt.Sprintf("local%d", slot)
+}
+
+// loop is the innermost loop being emitted, with the address of its header
+// and the address following it. exited is set when a break is emitted.
+type loop struct {
+	head, exit int
+	exited     bool
+}
+
+type funcWriter struct {
+	d     *decompiler
+	f     *function
+	b     *strings.Builder
+	depth int
+	// labels are the blocks that must be labeled, and gotos the targets of
+	// the gotos emitted so far.
+	labels map[int]bool
+	gotos  map[int]bool
+	// delta is the current relative base offset, if known.
+	delta   int
+	knownRB bool
+}
+
+func (d *decompiler) decompileFunction(b *strings.Builder, entry int) {
+	f := d.newFunction(entry)
+	// The first pass discovers which blocks are targets of gotos.
+	fw := &funcWriter{d: d, f: f, b: new(strings.Builder), gotos: make(map[int]bool)}
+	fw.emitFunction()
+	fw = &funcWriter{d: d, f: f, b: b, labels: fw.gotos, gotos: make(map[int]bool)}
+	fw.emitFunction()
+}
+
+func (fw *funcWriter) line(format string, args ...interface{}) {
+	fw.b.WriteString(strings.Repeat("\t", fw.depth))
+	fmt.Fprintf(fw.b, format, args...)
+	fw.b.WriteString("\n")
+}
+
+func (fw *funcWriter) emitFunction() {
+	f := fw.f
+	var params []string
+	for i := 1; i <= f.numArgs; i++ {
+		params = append(params, f.slotName(i))
+	}
+	if len(params) > 0 {
+		params[len(params)-1] += " int"
+	}
+	fw.line("// %d", f.entry)
+	fw.line("func %s(%s) {", f.name(), strings.Join(params, ", "))
+	fw.depth++
+	fw.emitRange(f.entry, int(^uint(0)>>1), -1, nil)
+	fw.depth--
+	fw.line("}")
+}
+
+// emitRange emits the blocks starting within [lo, hi). next is where control
+// goes after the range, so a jump there at the end needs no statement.
+func (fw *funcWriter) emitRange(lo, hi, next int, l *loop) {
+	addr, ok := fw.f.nextBlock(lo)
+	for ok && addr < hi {
+		follow := fw.emitFrom(addr, hi, next, l)
+		addr, ok = fw.f.nextBlock(follow)
+	}
+}
+
+// atEnd returns whether there is no block of the range after addr.
+func (fw *funcWriter) atEnd(addr, hi int) bool {
+	next, ok := fw.f.nextBlock(addr)
+	return !ok || next >= hi
+}
+
+// transfer returns the statement that moves control to target. Jumping to
+// next is implicit at the end of a range.
+func (fw *funcWriter) transfer(target, next int, l *loop, atEnd bool) string {
+	switch {
+	case atEnd && target == next:
+		return ""
+	case l != nil && target == l.head:
+		return "continue"
+	case l != nil && target == l.exit:
+		l.exited = true
+		return "break"
+	}
+	fw.gotos[target] = true
+	return fmt.Sprintf("goto L%d", target)
+}
+
+func (fw *funcWriter) emitTransfer(target, next int, l *loop, atEnd bool) {
+	if stmt := fw.transfer(target, next, l, atEnd); stmt != "" {
+		fw.line("%s", stmt)
+	}
+}
+
+// backEdge returns the end of the last block within [addr, hi) that jumps
+// back to addr.
+func (fw *funcWriter) backEdge(addr, hi int) (int, bool) {
+	end, found := 0, false
+	for _, start := range fw.f.starts {
+		if start < addr || start >= hi {
+			continue
+		}
+		block := fw.f.blocks[start]
+		for _, e := range block.Edges {
+			if e.Kind == Jump && e.To == addr && block.End > end {
+				end, found = block.End, true
+			}
+		}
+	}
+	return end, found
+}
+
+// emitFrom emits the structure starting at the block at addr, returning the
+// address where the rest of the range continues.
+func (fw *funcWriter) emitFrom(addr, hi, next int, l *loop) int {
+	if l == nil || l.head != addr {
+		if end, ok := fw.backEdge(addr, hi); ok {
+			fw.emitLabel(addr)
+			inner := &loop{head: addr, exit: end}
+			fw.line("for {")
+			fw.depth++
+			fw.emitRange(addr, end, addr, inner)
+			fw.depth--
+			fw.line("}")
+			if inner.exited && fw.atEnd(end, hi) {
+				fw.emitTransfer(end, next, l, true)
+			}
+			return end
+		}
+	}
+	block := fw.f.blocks[addr]
+	if !(l != nil && l.head == addr && fw.labels[addr]) {
+		fw.emitLabel(addr)
+	}
+	delta, known := fw.f.delta[addr]
+	fw.delta, fw.knownRB = delta, known
+	fw.emitStatements(block)
+	return fw.emitTerminator(block, hi, next, l)
+}
+
+func (fw *funcWriter) emitLabel(addr int) {
+	if fw.labels[addr] {
+		fw.depth--
+		fw.line("L%d:", addr)
+		fw.depth++
+	}
+}
+
+// operand returns the expression of a parameter.
+func (fw *funcWriter) operand(mode ParamMode, value int) string {
+	switch mode {
+	case Immediate:
+		return strconv.Itoa(value)
+	case Relative:
+		if !fw.knownRB {
+			return fmt.Sprintf("rb[%d]", value)
+		}
+		if fw.f.sub {
+			return fw.f.slotName(fw.delta + value)
+		}
+		value += fw.delta
+	}
+	if fw.d.g.IsCode(value) {
+		return fmt.Sprintf("code[%d]", value)
+	}
+	return fmt.Sprintf("g%d", value)
+}
+
+// param returns the expression of the i-th parameter of instr. Parameters
+// that the program overwrites are read from memory.
+func (fw *funcWriter) param(instr instruction, i int) string {
+	addr := instr.address + 1 + i
+	if !fw.d.dynamic[addr] {
+		return fw.operand(instr.modes[i], instr.params[i])
+	}
+	switch instr.modes[i] {
+	case Immediate:
+		return fmt.Sprintf("code[%d]", addr)
+	case Relative:
+		return fmt.Sprintf("mem[rb+code[%d]]", addr)
+	}
+	return fmt.Sprintf("mem[code[%d]]", addr)
+}
+
+// condition returns the condition under which a jump is taken. If the jump
+// reads a flag set by the previous compare, it's fused with it.
+func (fw *funcWriter) condition(prev *instruction, jump instruction) string {
+	var a, b, op string
+	if prev != nil && jump.modes[0] == Address && fw.d.flags[jump.params[0]] {
+		a, b = fw.param(*prev, 0), fw.param(*prev, 1)
+		op = "<"
+		if prev.opcode == Equals {
+			op = "=="
+		}
+		if jump.opcode == JumpIfZero {
+			op = map[string]string{"<": ">=", "==": "!="}[op]
+		}
+	} else {
+		a, b = fw.param(jump, 0), "0"
+		op = "!="
+		if jump.opcode == JumpIfZero {
+			op = "=="
+		}
+	}
+	return fmt.Sprintf("%s %s %s", a, op, b)
+}
+
+var negatedOps = map[string]string{
+	"<": ">=", ">=": "<", "==": "!=", "!=": "==",
+}
+
+func negate(cond string) string {
+	parts := strings.SplitN(cond, " ", 3)
+	return parts[0] + " " + negatedOps[parts[1]] + " " + parts[2]
+}
+
+// binary is an expression a op b, or just a if op is empty.
+type binary struct {
+	a, op, b string
+}
+
+func (e binary) String() string {
+	if e.op == "" {
+		return e.a
+	}
+	return e.a + " " + e.op + " " + e.b
+}
+
+// assignment returns the statement storing e in dst, using the shorter Go
+// forms when dst is also an operand.
+func assignment(dst string, e binary) string {
+	if e.op == "" {
+		return dst + " = " + e.a
+	}
+	if e.b == dst && (e.op == "+" || e.op == "*") {
+		e.a, e.b = e.b, e.a
+	}
+	if e.a != dst {
+		return dst + " = " + e.String()
+	}
+	switch {
+	case e.op == "+" && e.b == "1":
+		return dst + "++"
+	case e.op == "-" && e.b == "1":
+		return dst + "--"
+	}
+	return fmt.Sprintf("%s %s= %s", dst, e.op, e.b)
+}
+
+// arithmetic returns the simplified value computed by an add, mul or
+// compare, with constants on the right.
+func (fw *funcWriter) arithmetic(instr instruction) binary {
+	a, b := fw.param(instr, 0), fw.param(instr, 1)
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	switch instr.opcode {
+	case LessThan:
+		return binary{a: fmt.Sprintf("b2i(%s < %s)", a, b)}
+	case Equals:
+		return binary{a: fmt.Sprintf("b2i(%s == %s)", a, b)}
+	case Add:
+		switch {
+		case errA == nil && errB == nil:
+			return binary{a: strconv.Itoa(x + y)}
+		case errA == nil:
+			a, b, y = b, a, x
+		case errB != nil:
+			return binary{a, "+", b}
+		}
+		switch {
+		case y == 0:
+			return binary{a: a}
+		case y < 0:
+			return binary{a, "-", strconv.Itoa(-y)}
+		}
+		return binary{a, "+", b}
+	case Mul:
+		switch {
+		case errA == nil && errB == nil:
+			return binary{a: strconv.Itoa(x * y)}
+		case errA == nil:
+			a, b, y = b, a, x
+		case errB != nil:
+			return binary{a, "*", b}
+		}
+		switch y {
+		case 0:
+			return binary{a: "0"}
+		case 1:
+			return binary{a: a}
+		case -1:
+			return binary{a: "-" + a}
+		}
+		return binary{a, "*", b}
+	}
+	panic(fmt.Sprintf("Not an arithmetic instruction: %v", instr))
+}
+
+// statement returns the pseudo-code of a non-jump instruction, or "" if it
+// has no visible effect.
+func (fw *funcWriter) statement(instr instruction) string {
+	switch instr.opcode {
+	case Input:
+		return fmt.Sprintf("%s = input()", fw.param(instr, 0))
+	case Output:
+		return fmt.Sprintf("output(%s)", fw.param(instr, 0))
+	case Halt:
+		return "halt()"
+	case OffsetRelBase:
+		if fw.d.constantOffset(instr) {
+			return ""
+		}
+		// The operand may be relative to the base before it moves.
+		stmt := fmt.Sprintf("rb += %s", fw.param(instr, 0))
+		fw.knownRB = false
+		return stmt
+	}
+	return assignment(fw.param(instr, 2), fw.arithmetic(instr))
+}
+
+// skipped returns the instructions of a block that are not emitted as
+// statements: fused compares and call setup.
+func (fw *funcWriter) skipped(block *BasicBlock) map[int]bool {
+	skip := make(map[int]bool)
+	n := len(block.Instructions)
+	last := fw.d.g.instrs[block.Instructions[n-1]]
+	if last.isJump() && n > 1 {
+		prev := fw.d.g.instrs[block.Instructions[n-2]]
+		if isCompare(prev) && prev.modes[2] == Address && fw.d.flags[prev.params[2]] {
+			skip[prev.address] = true
+		}
+	}
+	if _, ok := callTarget(block); ok {
+		skip[block.Instructions[n-2]] = true
+		for _, addr := range fw.d.callArgs(block) {
+			skip[addr] = true
+		}
+	}
+	return skip
+}
+
+func (fw *funcWriter) emitStatements(block *BasicBlock) {
+	skip := fw.skipped(block)
+	n := len(block.Instructions)
+	for i, addr := range block.Instructions {
+		instr := fw.d.g.instrs[addr]
+		if instr.isJump() && i == n-1 {
+			break
+		}
+		if instr.opcode == OffsetRelBase && fw.d.constantOffset(instr) {
+			fw.delta += instr.params[0]
+		}
+		if skip[addr] {
+			continue
+		}
+		if stmt := fw.statement(instr); stmt != "" {
+			fw.line("%s", stmt)
+		}
+	}
+}
+
+func (fw *funcWriter) emitCall(block *BasicBlock, target int) {
+	args := fw.d.callArgs(block)
+	strs := make([]string, len(args))
+	for slot, addr := range args {
+		instr := fw.d.g.instrs[addr]
+		if instr.opcode == Input {
+			strs[slot-1] = "input()"
+		} else {
+			strs[slot-1] = fw.arithmetic(instr).String()
+		}
+	}
+	fw.line("f%d(%s)", target, strings.Join(strs, ", "))
+}
+
+func (fw *funcWriter) emitTerminator(block *BasicBlock, hi, next int, l *loop) int {
+	n := len(block.Instructions)
+	last := fw.d.g.instrs[block.Instructions[n-1]]
+	atEnd := fw.atEnd(block.End, hi)
+	if target, ok := callTarget(block); ok {
+		fw.emitCall(block, target)
+		fw.emitFallthrough(block, next, l, atEnd)
+		return block.End
+	}
+	switch {
+	case last.opcode == Halt:
+		return block.End
+	case block.Returns:
+		fw.line("return")
+		return block.End
+	case block.Indirect:
+		fw.line("goto *%s", fw.param(last, 1))
+		return block.End
+	case !last.isJump():
+		fw.emitFallthrough(block, next, l, atEnd)
+		return block.End
+	}
+	taken, falls := last.branches()
+	target := last.params[1]
+	if !falls {
+		fw.emitTransfer(target, next, l, atEnd)
+		return block.End
+	}
+	if !taken {
+		fw.emitFallthrough(block, next, l, atEnd)
+		return block.End
+	}
+	var prev *instruction
+	if n > 1 {
+		p := fw.d.g.instrs[block.Instructions[n-2]]
+		prev = &p
+	}
+	cond := fw.condition(prev, last)
+	switch {
+	case atEnd && target == next:
+		// Fall through leaves the range, jumping continues after it.
+		if stmt := fw.transfer(block.End, next, l, false); stmt != "" {
+			fw.line("if %s {", negate(cond))
+			fw.depth++
+			fw.line("%s", stmt)
+			fw.depth--
+			fw.line("}")
+		}
+		return block.End
+	case l != nil && (target == l.head || target == l.exit):
+		fw.line("if %s {", cond)
+		fw.depth++
+		fw.emitTransfer(target, next, l, false)
+		fw.depth--
+		fw.line("}")
+		fw.emitFallthrough(block, next, l, atEnd)
+		return block.End
+	case target > block.End && target <= hi && fw.f.blocks[target] != nil:
+		return fw.emitIf(block, negate(cond), target, hi, next, l)
+	}
+	fw.line("if %s {", cond)
+	fw.depth++
+	fw.emitTransfer(target, next, l, false)
+	fw.depth--
+	fw.line("}")
+	fw.emitFallthrough(block, next, l, atEnd)
+	return block.End
+}
+
+func (fw *funcWriter) emitFallthrough(block *BasicBlock, next int, l *loop, atEnd bool) {
+	if start, ok := fw.f.nextBlock(block.End); atEnd || !ok || start != block.End {
+		fw.emitTransfer(block.End, next, l, atEnd)
+	}
+}
+
+// emitIf emits the blocks in [block.End, target) as the body of an if, and
+// those after target as an else if the body ends jumping over them.
+func (fw *funcWriter) emitIf(block *BasicBlock, cond string, target, hi, next int, l *loop) int {
+	follow := target
+	var elseEnd int
+	hasElse := false
+	if lastStart, ok := fw.lastBlockBefore(target); ok && lastStart >= block.End {
+		last := fw.f.blocks[lastStart]
+		jump := fw.d.g.instrs[last.Instructions[len(last.Instructions)-1]]
+		if jump.isJump() && !last.Returns && !last.Indirect {
+			taken, falls := jump.branches()
+			dest := jump.params[1]
+			if _, isCall := callTarget(last); taken && !falls && !isCall && dest > target && dest <= hi && (l == nil || dest != l.exit) {
+				elseEnd, hasElse = dest, true
+				follow = dest
+			}
+		}
+	}
+	then := fw.capture(block.End, target, follow, l)
+	var els string
+	if hasElse {
+		els = fw.capture(target, elseEnd, follow, l)
+	}
+	if then == "" && els != "" {
+		cond, then, els = negate(cond), els, ""
+	}
+	fw.line("if %s {", cond)
+	fw.b.WriteString(then)
+	if els != "" {
+		fw.line("} else {")
+		fw.b.WriteString(els)
+	}
+	fw.line("}")
+	if fw.atEnd(follow, hi) {
+		fw.emitTransfer(follow, next, l, true)
+	}
+	return follow
+}
+
+// capture returns the code of a range nested one level deeper.
+func (fw *funcWriter) capture(lo, hi, next int, l *loop) string {
+	b := fw.b
+	fw.b = new(strings.Builder)
+	fw.depth++
+	fw.emitRange(lo, hi, next, l)
+	fw.depth--
+	code := fw.b.String()
+	fw.b = b
+	return code
+}
+
+// lastBlockBefore returns the start of the last block of f starting before
+// addr.
+func (fw *funcWriter) lastBlockBefore(addr int) (int, bool) {
+	i := sort.SearchInts(fw.f.starts, addr)
+	if i == 0 {
+		return 0, false
+	}
+	return fw.f.starts[i-1], true
+}
@@ -0,0 +1,47 @@
+package intcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecompileRelativeBase(t *testing.T) {
+	tests := []struct {
+		name    string
+		program string
+		want    []string
+	}{
+		{
+			"operand read before base moves",
+			"109,100,209,5,99",
+			[]string{"rb += g105"},
+		},
+		{
+			"relative cells in main named as memory",
+			"109,100,203,5,1001,105,1,105,4,105,99",
+			[]string{"g105 = input()", "output(g105)"},
+		},
+		{
+			"base unknown after variable offset in another block",
+			"109,100,209,20,1105,1,7,203,0,99",
+			[]string{"rb += g120", "rb[0] = input()"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := Decompile(&b, ParseProgram(test.program)); err != nil {
+				t.Fatal(err)
+			}
+			got := b.String()
+			for _, want := range test.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("missing %q in:\n%s", want, got)
+				}
+			}
+			if strings.Contains(got, "local") {
+				t.Errorf("main has locals:\n%s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package intcode
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var modePrefix = map[ParamMode]string{
+	Address:   "[",
+	Immediate: "#",
+	Relative:  "rb[",
+}
+
+var modeSuffix = map[ParamMode]string{
+	Address:   "]",
+	Immediate: "",
+	Relative:  "]",
+}
+
+func formatOperand(mode ParamMode, value int) string {
+	return modePrefix[mode] + strconv.Itoa(value) + modeSuffix[mode]
+}
+
+type instruction struct {
+	address int
+	opcode  InstructionType
+	modes   []ParamMode
+	params  []int
+}
+
+func (instr instruction) size() int {
+	return len(instr.params) + 1
+}
+
+func (instr instruction) String() string {
+	operands := make([]string, len(instr.params))
+	for i, param := range instr.params {
+		operands[i] = formatOperand(instr.modes[i], param)
+	}
+	return strings.TrimSpace(instructionNames[instr.opcode] + " " + strings.Join(operands, ", "))
+}
+
+// decodeAt returns the instruction at addr, if the word there is a valid
+// instruction in canonical form whose parameters fit within the program.
+func decodeAt(program []int, addr int) (instruction, bool) {
+	if addr < 0 || addr >= len(program) {
+		return instruction{}, false
+	}
+	opcode, modes, err := decodeInstruction(program[addr])
+	if err != nil {
+		return instruction{}, false
+	}
+	if encodeInstruction(opcode, modes) != program[addr] {
+		return instruction{}, false
+	}
+	if addr+len(modes) >= len(program) {
+		return instruction{}, false
+	}
+	expectedModes := expectedParamModes[opcode]
+	for i, mode := range modes {
+		if mode == Immediate && expectedModes[i] == Address {
+			return instruction{}, false
+		}
+	}
+	return instruction{
+		address: addr,
+		opcode:  opcode,
+		modes:   modes,
+		params:  program[addr+1 : addr+1+len(modes)],
+	}, true
+}
+
+func joinInts(xs []int, sep string) string {
+	strs := make([]string, len(xs))
+	for i, x := range xs {
+		strs[i] = strconv.Itoa(x)
+	}
+	return strings.Join(strs, sep)
+}
+
+const maxDataPerLine = 8
+
+// Disassemble writes an annotated listing of program to w. Words that don't
+// decode to a valid instruction are emitted as .data, so that the listing
+// assembles back to the same program.
+func Disassemble(w io.Writer, program []int) error {
+	return disassemble(w, program, nil, nil)
+}
+
+// disassemble writes a listing where each address in entries starts a line,
+// even if it lies within what would otherwise be decoded as an instruction.
+// annotate, if not nil, returns extra text for the comment of an instruction.
+func disassemble(w io.Writer, program []int, entries map[int]bool, annotate func(addr int) string) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	var data []int
+	dataAddr := 0
+	flushData := func() {
+		if len(data) == 0 {
+			return
+		}
+		fmt.Fprintf(tw, "\t.data %s\t; %d\n", joinInts(data, ", "), dataAddr)
+		data = nil
+	}
+	overlapsEntry := func(instr instruction) bool {
+		for addr := instr.address + 1; addr < instr.address+instr.size(); addr++ {
+			if entries[addr] {
+				return true
+			}
+		}
+		return false
+	}
+	for addr := 0; addr < len(program); {
+		if entries[addr] {
+			flushData()
+		}
+		instr, ok := decodeAt(program, addr)
+		if !ok || overlapsEntry(instr) {
+			if len(data) == maxDataPerLine {
+				flushData()
+			}
+			if len(data) == 0 {
+				dataAddr = addr
+			}
+			data = append(data, program[addr])
+			addr++
+			continue
+		}
+		flushData()
+		fmt.Fprintf(tw, "\t%v\t; %d: %s", instr, addr, joinInts(program[addr:addr+instr.size()], ","))
+		if annotate != nil {
+			fmt.Fprintf(tw, "\t%s", annotate(addr))
+		}
+		fmt.Fprintln(tw)
+		addr += instr.size()
+	}
+	flushData()
+	return tw.Flush()
+}
+
+// DisassembleString returns the listing produced by Disassemble.
+func DisassembleString(program []int) string {
+	b := new(strings.Builder)
+	Disassemble(b, program)
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrHalted is how a single instruction step reports that the program reached
+// a halt instruction. Run, RunUntil and Debugger turn it into their own halted
+// results rather than returning it.
+var ErrHalted = errors.New("Halted")
+
+// ErrEmptyProgram is returned when parsing a program without any value.
+var ErrEmptyProgram = errors.New("Empty program")
+
+type InvalidOpcodeError struct {
+	Address     int
+	Instruction int
+	Registers   Registers
+}
+
+func (e *InvalidOpcodeError) Error() string {
+	return fmt.Sprintf("Unknown opcode %d @ %d (%d, %v)", e.Instruction%100, e.Address, e.Instruction, e.Registers)
+}
+
+// InvalidModeError is returned for an unknown mode digit, or for immediate
+// mode in a param that is written to. Param is 1-based.
+type InvalidModeError struct {
+	Address     int
+	Instruction int
+	Param       int
+	Mode        int
+	Registers   Registers
+}
+
+func (e *InvalidModeError) Error() string {
+	if ParamMode(e.Mode) == Immediate {
+		return fmt.Sprintf("Unexpected immediate mode for param #%d @ %d (%d, %v)", e.Param, e.Address, e.Instruction, e.Registers)
+	}
+	return fmt.Sprintf("Unknown mode %d for param #%d @ %d (%d, %v)", e.Mode, e.Param, e.Address, e.Instruction, e.Registers)
+}
+
+// InputExhaustedError is returned when the input reader has no more values.
+// If the reader has an Err method, its result is kept in Err. Run doesn't
+// return it for readers that report io.EOF, like closed pipes.
+type InputExhaustedError struct {
+	Address     int
+	Instruction int
+	Registers   Registers
+	Err         error
+}
+
+func (e *InputExhaustedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("Input exhausted @ %d (%v): %v", e.Address, e.Registers, e.Err)
+	}
+	return fmt.Sprintf("Input exhausted @ %d (%v)", e.Address, e.Registers)
+}
+
+func (e *InputExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidAddressError is returned when an instruction accesses a negative
+// address.
+type InvalidAddressError struct {
+	Address     int
+	Instruction int
+	Target      int
+	Registers   Registers
+}
+
+func (e *InvalidAddressError) Error() string {
+	return fmt.Sprintf("Negative address %d @ %d (%d, %v)", e.Target, e.Address, e.Instruction, e.Registers)
+}
+
+// MemoryLimitError is returned when an instruction accesses an address beyond
+// the computer's memory limit.
+type MemoryLimitError struct {
+	Address     int
+	Instruction int
+	Target      int
+	Limit       int
+	Registers   Registers
+}
+
+func (e *MemoryLimitError) Error() string {
+	return fmt.Sprintf("Address %d exceeds memory limit of %d words @ %d (%d, %v)", e.Target, e.Limit, e.Address, e.Instruction, e.Registers)
+}
+
+// InstructionLimitError is returned when a computer would execute more
+// instructions than its MaxInstructions.
+type InstructionLimitError struct {
+	Address   int
+	Limit     int
+	Registers Registers
+}
+
+func (e *InstructionLimitError) Error() string {
+	return fmt.Sprintf("Instruction limit of %d reached @ %d (%v)", e.Limit, e.Address, e.Registers)
+}
+
+// OverflowError is returned by computers with CheckOverflow when the result
+// of an add or mul doesn't fit in an int.
+type OverflowError struct {
+	Address     int
+	Instruction int
+	Opcode      InstructionType
+	A, B        int
+	Registers   Registers
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("Overflow in %s %d, %d @ %d (%d, %v)", instructionNames[e.Opcode], e.A, e.B, e.Address, e.Instruction, e.Registers)
+}
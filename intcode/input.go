@@ -1,22 +1,134 @@
 package intcode
 
 import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 )
 
-func ParseProgram(s string) []int {
-	strs := strings.Split(s, ",")
-	ints := make([]int, len(strs))
-	for i, s := range strs {
-		v, err := strconv.Atoi(s)
+// ParseError reports an invalid token in a program's text. Line and Column
+// are 1-based, and Column counts bytes.
+type ParseError struct {
+	Offset int
+	Line   int
+	Column int
+	Token  string
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("Missing value at line %d, column %d", e.Line, e.Column)
+	}
+	return fmt.Sprintf("Invalid value %q at line %d, column %d", e.Token, e.Line, e.Column)
+}
+
+func newParseError(s string, offset int, token string) *ParseError {
+	lineStart := strings.LastIndex(s[:offset], "\n") + 1
+	return &ParseError{
+		Offset: offset,
+		Line:   strings.Count(s[:offset], "\n") + 1,
+		Column: offset - lineStart + 1,
+		Token:  token,
+	}
+}
+
+func isSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
+		return true
+	}
+	return false
+}
+
+// parseProgram parses integers separated by a comma, whitespace or both.
+func parseProgram(s string) ([]int, error) {
+	var ints []int
+	// afterComma is set when a value must follow.
+	afterComma := false
+	for i := 0; ; {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i == len(s) {
+			if afterComma {
+				return nil, newParseError(s, i, "")
+			}
+			break
+		}
+		if s[i] == ',' {
+			if afterComma || len(ints) == 0 {
+				return nil, newParseError(s, i, "")
+			}
+			afterComma = true
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && s[i] != ',' && !isSpace(s[i]) {
+			i++
+		}
+		token := s[start:i]
+		v, err := strconv.Atoi(token)
 		if err != nil {
-			panic(err.Error())
+			return nil, newParseError(s, start, token)
 		}
-		ints[i] = v
+		ints = append(ints, v)
+		afterComma = false
+	}
+	if len(ints) == 0 {
+		return nil, ErrEmptyProgram
+	}
+	return ints, nil
+}
+
+// ParseProgram parses a list of integers separated by commas or whitespace,
+// such as newlines, panicking if it's invalid or empty.
+func ParseProgram(s string) []int {
+	ints, err := parseProgram(s)
+	if err != nil {
+		panic(err.Error())
 	}
 	return ints
 }
 
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// LoadProgram reads a program from r, which may be gzip-compressed.
+func LoadProgram(r io.Reader) ([]int, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(gzipMagic)); string(magic) == string(gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseProgram(string(bs))
+}
+
+func LoadProgramFile(path string) ([]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	program, err := LoadProgram(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return program, nil
+}
+
 const day2Input = `1,95,7,3,1,1,2,3,1,3,4,3,1,5,0,3,2,1,6,19,1,19,5,23,2,13,23,27,1,10,27,31,2,6,31,35,1,9,35,39,2,10,39,43,1,43,9,47,1,47,9,51,2,10,51,55,1,55,9,59,1,59,5,63,1,63,6,67,2,6,67,71,2,10,71,75,1,75,5,79,1,9,79,83,2,83,10,87,1,87,6,91,1,13,91,95,2,10,95,99,1,99,6,103,2,13,103,107,1,107,2,111,1,111,9,0,99,2,14,0,0`
 const day5Input = `3,225,1,225,6,6,1100,1,238,225,104,0,1102,79,14,225,1101,17,42,225,2,74,69,224,1001,224,-5733,224,4,224,1002,223,8,223,101,4,224,224,1,223,224,223,1002,191,83,224,1001,224,-2407,224,4,224,102,8,223,223,101,2,224,224,1,223,224,223,1101,18,64,225,1102,63,22,225,1101,31,91,225,1001,65,26,224,101,-44,224,224,4,224,102,8,223,223,101,3,224,224,1,224,223,223,101,78,13,224,101,-157,224,224,4,224,1002,223,8,223,1001,224,3,224,1,224,223,223,102,87,187,224,101,-4698,224,224,4,224,102,8,223,223,1001,224,4,224,1,223,224,223,1102,79,85,224,101,-6715,224,224,4,224,1002,223,8,223,1001,224,2,224,1,224,223,223,1101,43,46,224,101,-89,224,224,4,224,1002,223,8,223,101,1,224,224,1,223,224,223,1101,54,12,225,1102,29,54,225,1,17,217,224,101,-37,224,224,4,224,102,8,223,223,1001,224,3,224,1,223,224,223,1102,20,53,225,4,223,99,0,0,0,677,0,0,0,0,0,0,0,0,0,0,0,1105,0,99999,1105,227,247,1105,1,99999,1005,227,99999,1005,0,256,1105,1,99999,1106,227,99999,1106,0,265,1105,1,99999,1006,0,99999,1006,227,274,1105,1,99999,1105,1,280,1105,1,99999,1,225,225,225,1101,294,0,0,105,1,0,1105,1,99999,1106,0,300,1105,1,99999,1,225,225,225,1101,314,0,0,106,0,0,1105,1,99999,107,226,226,224,1002,223,2,223,1006,224,329,101,1,223,223,1108,677,226,224,1002,223,2,223,1006,224,344,101,1,223,223,7,677,226,224,102,2,223,223,1006,224,359,101,1,223,223,108,226,226,224,1002,223,2,223,1005,224,374,101,1,223,223,8,226,677,224,1002,223,2,223,1006,224,389,101,1,223,223,1108,226,226,224,102,2,223,223,1006,224,404,101,1,223,223,1007,677,677,224,1002,223,2,223,1006,224,419,101,1,223,223,8,677,677,224,1002,223,2,223,1005,224,434,1001,223,1,223,1008,226,226,224,102,2,223,223,1005,224,449,1001,223,1,223,1008,226,677,224,102,2,223,223,1006,224,464,101,1,223,223,1107,677,677,224,102,2,223,223,1006,224,479,101,1,223,223,107,677,677,224,1002,223,2,223,1005,224,494,1001,223,1,223,1107,226,677,224,1002,223,2,223,1005,224,509,101,1,223,223,1108,226,677,224,102,2,223,223,1006,224,524,101,1,223,223,7,226,226,224,1002,223,2,223,1005,224,539,101,1,223,223,108,677,677,224,1002,223,2,223,1005,224,554,101,1,223,223,8,677,226,224,1002,223,2,223,1005,224,569,1001,223,1,223,1008,677,677,224,102,2,223,223,1006,224,584,101,1,223,223,107,226,677,224,102,2,223,223,1005,224,599,1001,223,1,223,7,226,677,224,102,2,223,223,1005,224,614,101,1,223,223,1007,226,226,224,1002,223,2,223,1005,224,629,101,1,223,223,1107,677,226,224,1002,223,2,223,1006,224,644,101,1,223,223,108,226,677,224,102,2,223,223,1006,224,659,101,1,223,223,1007,677,226,224,102,2,223,223,1006,224,674,101,1,223,223,4,223,99,226`
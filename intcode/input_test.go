@@ -0,0 +1,92 @@
+package intcode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseProgram(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []int
+	}{
+		{"commas", "1,2,-3", []int{1, 2, -3}},
+		{"spaces around commas", " 1 , 2,\t3 ", []int{1, 2, 3}},
+		{"trailing newline", "1,2,3\n", []int{1, 2, 3}},
+		{"newline after comma", "1,2,\n3\r\n", []int{1, 2, 3}},
+		{"newlines", "1\n2\n3", []int{1, 2, 3}},
+		{"spaces", "1 2  3", []int{1, 2, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseProgram(test.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseProgramErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want ParseError
+	}{
+		{"invalid value", "1,x,3", ParseError{Offset: 2, Line: 1, Column: 3, Token: "x"}},
+		{"invalid value on line 2", "1,2,\n 3,4a", ParseError{Offset: 8, Line: 2, Column: 4, Token: "4a"}},
+		{"double comma", "1,,2", ParseError{Offset: 2, Line: 1, Column: 3}},
+		{"leading comma", "\n,1", ParseError{Offset: 1, Line: 2, Column: 1}},
+		{"trailing comma", "1,2,\n", ParseError{Offset: 5, Line: 2, Column: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseProgram(test.s)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("got %v, want ParseError", err)
+			}
+			if *parseErr != test.want {
+				t.Errorf("got %+v, want %+v", *parseErr, test.want)
+			}
+		})
+	}
+	for _, s := range []string{"", " \n"} {
+		if _, err := parseProgram(s); err != ErrEmptyProgram {
+			t.Errorf("parsing %q: got %v, want %v", s, err, ErrEmptyProgram)
+		}
+	}
+}
+
+func TestLoadProgramGzip(t *testing.T) {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	gz.Write([]byte("1,2,3\n4\n"))
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, r := range map[string]*bytes.Reader{
+		"gzip":  bytes.NewReader(b.Bytes()),
+		"plain": bytes.NewReader([]byte("1,2,3\n4\n")),
+	} {
+		got, err := LoadProgram(r)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+	if _, err := LoadProgram(strings.NewReader("\x1f\x8bnot gzip")); err == nil {
+		t.Errorf("no error for corrupt gzip")
+	}
+}
@@ -0,0 +1,99 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countTo100 increments [12] until it's 100, with a compare and jump that
+// may be fused. It executes 301 instructions, including the halt.
+const countTo100 = "1001,12,1,12,1007,12,100,13,1005,13,0,99,0,0"
+
+func TestInstructionLimit(t *testing.T) {
+	tests := []struct {
+		limit   int
+		address int
+	}{
+		{1, 4},
+		{9, 0},
+		{10, 4},
+		{11, 8},
+		{300, 11},
+	}
+	for _, config := range configs {
+		for _, test := range tests {
+			c := NewComputer(ParseProgram(countTo100))
+			config.configure(c)
+			c.MaxInstructions = test.limit
+			_, err := c.RunWith()
+			var limitErr *InstructionLimitError
+			if !errors.As(err, &limitErr) {
+				t.Errorf("%s: limit %d: got %v, want InstructionLimitError", config.name, test.limit, err)
+				continue
+			}
+			if limitErr.Limit != test.limit || limitErr.Address != test.address {
+				t.Errorf("%s: got limit %d @ %d, want %d @ %d", config.name, limitErr.Limit, limitErr.Address, test.limit, test.address)
+			}
+			if count := c.InstructionCount(); count != test.limit {
+				t.Errorf("%s: limit %d: executed %d instructions", config.name, test.limit, count)
+			}
+		}
+		c := NewComputer(ParseProgram(countTo100))
+		config.configure(c)
+		c.MaxInstructions = 301
+		if _, err := c.RunWith(); err != nil {
+			t.Errorf("%s: program that fits the limit failed: %v", config.name, err)
+		}
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"canceled", canceled, context.Canceled},
+		{"deadline", expired, context.DeadlineExceeded},
+	}
+	for _, test := range tests {
+		c := NewComputer(ParseProgram("1105,1,0"))
+		err := c.RunContext(test.ctx, &inout{}, &inout{})
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+		// The computer is left in a consistent state, and may be resumed.
+		c.MaxInstructions = c.InstructionCount() + 10
+		_, err = c.RunUntil()
+		var limitErr *InstructionLimitError
+		if !errors.As(err, &limitErr) || limitErr.Address != 0 {
+			t.Errorf("%s: resuming got %v, want InstructionLimitError @ 0", test.name, err)
+		}
+	}
+}
+
+func TestRunUntilContextCanceledWhileRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewComputer(ParseProgram("1105,1,0"))
+	done := make(chan error)
+	go func() {
+		_, err := c.RunUntilContext(ctx)
+		done <- err
+	}()
+	time.Sleep(time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("computer didn't stop after cancel")
+	}
+}
@@ -0,0 +1,62 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryGrows(t *testing.T) {
+	tests := []struct {
+		program string
+		want    []int
+	}{
+		{"1101,2,3,1000,4,1000,99", []int{5}},
+		{"4,500,99", []int{0}},
+		{"109,2000,21101,4,5,7,204,7,99", []int{9}},
+	}
+	for _, test := range tests {
+		got, err := NewComputer(ParseProgram(test.program)).RunWith()
+		if err != nil {
+			t.Errorf("%s: %v", test.program, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.program, got, test.want)
+		}
+	}
+}
+
+func TestMemoryErrors(t *testing.T) {
+	tests := []struct {
+		program   string
+		maxMemory int
+		target    int
+		limit     int
+	}{
+		{"4,-1,99", 0, -1, 0},
+		{"1101,1,1,-5,99", 0, -5, 0},
+		{"109,-10,204,3,99", 0, -7, 0},
+		{"1101,1,1,200,99", 100, 200, 100},
+		{"4,100,99", 100, 100, 100},
+	}
+	for _, test := range tests {
+		c := NewComputer(ParseProgram(test.program))
+		c.MaxMemory = test.maxMemory
+		_, err := c.RunWith()
+		var invalid *InvalidAddressError
+		var limit *MemoryLimitError
+		switch {
+		case test.limit == 0 && errors.As(err, &invalid):
+			if invalid.Target != test.target {
+				t.Errorf("%s: negative address %d, want %d", test.program, invalid.Target, test.target)
+			}
+		case test.limit > 0 && errors.As(err, &limit):
+			if limit.Target != test.target || limit.Limit != test.limit {
+				t.Errorf("%s: address %d beyond %d, want %d beyond %d", test.program, limit.Target, limit.Limit, test.target, test.limit)
+			}
+		default:
+			t.Errorf("%s: unexpected error %v", test.program, err)
+		}
+	}
+}
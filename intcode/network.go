@@ -0,0 +1,184 @@
+package intcode
+
+import (
+	"fmt"
+)
+
+type ExecutionMode int
+
+const (
+	// Serial runs nodes in turns on a single goroutine, each until it blocks
+	// on input or halts.
+	Serial ExecutionMode = iota
+	// Concurrent runs each node on its own goroutine.
+	Concurrent
+)
+
+type NodeResult struct {
+	Name    string
+	Outputs []int
+	Err     error
+}
+
+type networkNode struct {
+	name     string
+	computer *Computer
+	inputs   []int
+	targets  []*networkNode
+	result   *NodeResult
+}
+
+// Network is a set of computers where the outputs of a node are sent as
+// inputs to every node it's connected to.
+type Network struct {
+	nodes  []*networkNode
+	byName map[string]*networkNode
+}
+
+func NewNetwork() *Network {
+	return &Network{byName: make(map[string]*networkNode)}
+}
+
+// AddNode adds a computer running program, which will read the given inputs
+// before any value received from other nodes.
+func (n *Network) AddNode(name string, program []int, inputs ...int) error {
+	if _, ok := n.byName[name]; ok {
+		return fmt.Errorf("Duplicate node %q", name)
+	}
+	node := &networkNode{
+		name:     name,
+		computer: NewComputer(program),
+		inputs:   inputs,
+	}
+	n.nodes = append(n.nodes, node)
+	n.byName[name] = node
+	return nil
+}
+
+// Connect sends every output of node from as an input to node to.
+func (n *Network) Connect(from, to string) error {
+	src, ok := n.byName[from]
+	if !ok {
+		return fmt.Errorf("Unknown node %q", from)
+	}
+	dst, ok := n.byName[to]
+	if !ok {
+		return fmt.Errorf("Unknown node %q", to)
+	}
+	src.targets = append(src.targets, dst)
+	return nil
+}
+
+// Run executes all nodes until they halt or fail, returning the results by
+// node name. If all remaining nodes are blocked on input, Run returns a
+// DeadlockError along with the partial results. Computers keep their state
+// after running, so Run should be called only once.
+func (n *Network) Run(mode ExecutionMode) (map[string]*NodeResult, error) {
+	results := make(map[string]*NodeResult, len(n.nodes))
+	for _, node := range n.nodes {
+		node.result = &NodeResult{Name: node.name}
+		results[node.name] = node.result
+	}
+	var err error
+	switch mode {
+	case Serial:
+		err = n.runSerial()
+	case Concurrent:
+		err = n.runConcurrent()
+	default:
+		err = fmt.Errorf("Unknown execution mode: %d", mode)
+	}
+	return results, err
+}
+
+func (n *Network) runSerial() error {
+	for _, node := range n.nodes {
+		node.computer.AddInput(node.inputs...)
+	}
+	finished := make(map[*networkNode]bool)
+	for len(finished) < len(n.nodes) {
+		progress := false
+		var blocked []*networkNode
+		for _, node := range n.nodes {
+			if finished[node] {
+				continue
+			}
+			c := node.computer
+			before := c.InstructionCount()
+			status, err := n.runNode(node)
+			if err != nil {
+				node.result.Err = err
+			}
+			if err != nil || status == Halted {
+				finished[node] = true
+				progress = true
+				continue
+			}
+			if c.InstructionCount() != before {
+				progress = true
+			}
+			blocked = append(blocked, node)
+		}
+		if !progress {
+			err := &DeadlockError{}
+			for _, node := range blocked {
+				node.result.Err = node.computer.inputExhausted(nil)
+				err.Blocked = append(err.Blocked, BlockedMachine{
+					Name:      node.name,
+					Registers: node.computer.Registers(),
+					Channel:   "input of " + node.name,
+				})
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// runNode runs a node until it halts or requires input, sending its outputs
+// to its targets.
+func (n *Network) runNode(node *networkNode) (Status, error) {
+	for {
+		status, err := node.computer.RunUntil()
+		if err != nil || status != HasOutput {
+			return status, err
+		}
+		v, _ := node.computer.Output()
+		node.result.Outputs = append(node.result.Outputs, v)
+		for _, target := range node.targets {
+			target.computer.AddInput(v)
+		}
+	}
+}
+
+type networkOutput struct {
+	result   *NodeResult
+	channels []*Channel
+}
+
+func (o *networkOutput) PushInt(i int) {
+	o.result.Outputs = append(o.result.Outputs, i)
+	for _, ch := range o.channels {
+		ch.PushInt(i)
+	}
+}
+
+func (n *Network) runConcurrent() error {
+	s := NewScheduler()
+	channels := make(map[*networkNode]*Channel, len(n.nodes))
+	for _, node := range n.nodes {
+		channels[node] = s.NewChannel("input of "+node.name, node.inputs...)
+	}
+	for _, node := range n.nodes {
+		out := &networkOutput{result: node.result}
+		for _, target := range node.targets {
+			out.channels = append(out.channels, channels[target])
+		}
+		s.Add(node.name, node.computer, channels[node], out)
+	}
+	errs, err := s.Run()
+	for _, node := range n.nodes {
+		node.result.Err = errs[node.name]
+	}
+	return err
+}
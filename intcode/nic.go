@@ -0,0 +1,342 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Packet is a message between NICs. A NIC sends it by outputting Dest, X and
+// Y, and receives it by reading X and Y.
+type Packet struct {
+	Source, Dest int
+	X, Y         int
+}
+
+type NICStats struct {
+	Sent     int
+	Received int
+	// Polls is the number of reads that found an empty queue and got -1.
+	Polls int
+}
+
+// NICMonitor observes a NICNetwork, like the NAT of the puzzle. Its methods
+// are called with the network locked, so they must not call the network.
+type NICMonitor interface {
+	// Receive is called with packets sent to an address without a NIC.
+	// Returning false stops the network.
+	Receive(p Packet) bool
+	// Idle is called when all queues are empty and every NIC is polling. It
+	// returns packets to inject into the network, or false to stop it.
+	Idle() ([]Packet, bool)
+}
+
+// idlePolls is the number of consecutive empty reads after which a NIC is
+// considered idle. The first -1 may still cause the NIC to send something.
+const idlePolls = 2
+
+type nicNode struct {
+	address  int
+	computer *Computer
+	booted   bool
+	queue    []Packet
+	halfRead bool
+	partial  []int
+	polls    int
+	halted   bool
+	stats    NICStats
+}
+
+// NICNetwork runs one computer per address, each reading its own address
+// and then packets sent to it, or -1 when there's none.
+type NICNetwork struct {
+	// Monitor, if set, receives packets to unknown addresses and is notified
+	// when the network is idle. Without a monitor these packets are dropped
+	// and the network stops when idle.
+	Monitor NICMonitor
+	// Dropped counts packets sent to unknown addresses without a monitor.
+	Dropped int
+
+	mu      sync.Mutex
+	nics    []*nicNode
+	stopped bool
+	cancel  context.CancelFunc
+}
+
+// NewNICNetwork creates a network of size computers running program, with
+// addresses 0 to size-1.
+func NewNICNetwork(program []int, size int) *NICNetwork {
+	n := &NICNetwork{}
+	for i := 0; i < size; i++ {
+		n.nics = append(n.nics, &nicNode{address: i, computer: NewComputer(program)})
+	}
+	return n
+}
+
+// Send injects a packet into the network, as if sent from outside of it.
+func (n *NICNetwork) Send(p Packet) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.deliver(p)
+}
+
+// Stats returns the packet statistics of each NIC, indexed by address.
+func (n *NICNetwork) Stats() []NICStats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	stats := make([]NICStats, len(n.nics))
+	for i, nic := range n.nics {
+		stats[i] = nic.stats
+	}
+	return stats
+}
+
+func (n *NICNetwork) stop() {
+	n.stopped = true
+	if n.cancel != nil {
+		n.cancel()
+	}
+}
+
+func (n *NICNetwork) deliver(p Packet) {
+	if p.Dest >= 0 && p.Dest < len(n.nics) {
+		dst := n.nics[p.Dest]
+		dst.queue = append(dst.queue, p)
+		dst.polls = 0
+		return
+	}
+	if n.Monitor == nil {
+		n.Dropped++
+		return
+	}
+	if !n.Monitor.Receive(p) {
+		n.stop()
+	}
+}
+
+// nextInput returns the next value to be read by nic. Must be called with the
+// lock held.
+func (n *NICNetwork) nextInput(nic *nicNode) int {
+	if !nic.booted {
+		nic.booted = true
+		return nic.address
+	}
+	if nic.halfRead {
+		p := nic.queue[0]
+		nic.queue = nic.queue[1:]
+		nic.halfRead = false
+		nic.stats.Received++
+		return p.Y
+	}
+	if len(nic.queue) > 0 {
+		nic.halfRead = true
+		return nic.queue[0].X
+	}
+	nic.polls++
+	nic.stats.Polls++
+	if !n.stopped && n.isIdle() {
+		n.idle()
+	}
+	return -1
+}
+
+// output handles a value written by nic. Must be called with the lock held.
+func (n *NICNetwork) output(nic *nicNode, v int) {
+	nic.polls = 0
+	nic.partial = append(nic.partial, v)
+	if len(nic.partial) < 3 {
+		return
+	}
+	p := Packet{Source: nic.address, Dest: nic.partial[0], X: nic.partial[1], Y: nic.partial[2]}
+	nic.partial = nic.partial[:0]
+	nic.stats.Sent++
+	n.deliver(p)
+}
+
+func (n *NICNetwork) isIdle() bool {
+	running := false
+	for _, nic := range n.nics {
+		if nic.halted {
+			continue
+		}
+		if len(nic.queue) > 0 || len(nic.partial) > 0 || nic.polls < idlePolls {
+			return false
+		}
+		running = true
+	}
+	return running
+}
+
+func (n *NICNetwork) idle() {
+	if n.Monitor == nil {
+		n.stop()
+		return
+	}
+	packets, ok := n.Monitor.Idle()
+	if !ok || len(packets) == 0 {
+		n.stop()
+		return
+	}
+	for _, p := range packets {
+		n.deliver(p)
+	}
+}
+
+// Run executes the network until it's stopped by the monitor, becomes idle
+// without a monitor, or all NICs halt. In Serial mode, NICs run in address
+// order, each until it polls an empty queue, so runs are deterministic.
+func (n *NICNetwork) Run(mode ExecutionMode) error {
+	switch mode {
+	case Serial:
+		return n.runSerial()
+	case Concurrent:
+		return n.runConcurrent()
+	default:
+		return fmt.Errorf("Unknown execution mode: %d", mode)
+	}
+}
+
+func (n *NICNetwork) runSerial() error {
+	for {
+		running := false
+		for _, nic := range n.nics {
+			if nic.halted {
+				continue
+			}
+			if err := n.runTurn(nic); err != nil {
+				return fmt.Errorf("NIC %d: %w", nic.address, err)
+			}
+			n.mu.Lock()
+			stopped := n.stopped
+			n.mu.Unlock()
+			if stopped {
+				return nil
+			}
+			running = running || !nic.halted
+		}
+		if !running {
+			return nil
+		}
+	}
+}
+
+// runTurn runs nic until it polls an empty queue or halts.
+func (n *NICNetwork) runTurn(nic *nicNode) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	c := nic.computer
+	for {
+		status, err := c.RunUntil()
+		if err != nil {
+			return err
+		}
+		switch status {
+		case NeedsInput:
+			polls := nic.stats.Polls
+			c.AddInput(n.nextInput(nic))
+			if nic.stats.Polls != polls {
+				return nil
+			}
+		case HasOutput:
+			v, _ := c.Output()
+			n.output(nic, v)
+		case Halted:
+			nic.halted = true
+			return nil
+		}
+	}
+}
+
+type nicPort struct {
+	n   *NICNetwork
+	nic *nicNode
+}
+
+func (p nicPort) NextInt() (int, bool) {
+	p.n.mu.Lock()
+	defer p.n.mu.Unlock()
+	return p.n.nextInput(p.nic), true
+}
+
+func (p nicPort) PushInt(i int) {
+	p.n.mu.Lock()
+	defer p.n.mu.Unlock()
+	p.n.output(p.nic, i)
+}
+
+func (n *NICNetwork) runConcurrent() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n.mu.Lock()
+	n.cancel = cancel
+	n.mu.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(n.nics))
+	for i, nic := range n.nics {
+		wg.Add(1)
+		go func(i int, nic *nicNode) {
+			defer wg.Done()
+			port := nicPort{n, nic}
+			err := nic.computer.RunContext(ctx, port, port)
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			nic.halted = true
+			if err != nil && !errors.Is(err, context.Canceled) {
+				errs[i] = fmt.Errorf("NIC %d: %w", nic.address, err)
+				n.stop()
+				return
+			}
+			if !n.stopped && n.isIdle() {
+				n.idle()
+			}
+		}(i, nic)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NAT is a monitor that keeps the last packet sent to Address and, whenever
+// the network is idle, sends it to address 0. It stops the network when it
+// would send the same Y value twice in a row, or if it never received a
+// packet.
+type NAT struct {
+	Address int
+	// First and Last are the first and last packets received.
+	First, Last *Packet
+	// Sent is the sequence of Y values sent to address 0.
+	Sent []int
+}
+
+func NewNAT(address int) *NAT {
+	return &NAT{Address: address}
+}
+
+func (nat *NAT) Receive(p Packet) bool {
+	if p.Dest != nat.Address {
+		return true
+	}
+	if nat.First == nil {
+		nat.First = &p
+	}
+	nat.Last = &p
+	return true
+}
+
+func (nat *NAT) Idle() ([]Packet, bool) {
+	if nat.Last == nil {
+		return nil, false
+	}
+	y := nat.Last.Y
+	if len(nat.Sent) > 0 && nat.Sent[len(nat.Sent)-1] == y {
+		return nil, false
+	}
+	nat.Sent = append(nat.Sent, y)
+	return []Packet{{Source: nat.Address, Dest: 0, X: nat.Last.X, Y: y}}, true
+}
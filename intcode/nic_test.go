@@ -0,0 +1,118 @@
+package intcode
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// relayNIC returns a NIC program for a network of size computers. NIC 0
+// sends (1, 0, 0) on boot and, for each packet (x, y) it receives, sends
+// (1, x+1, 0). Every other NIC relays the packets it receives to the next
+// address, or to 255 from the last one, adding 1 to y.
+func relayNIC(t *testing.T, size int) []int {
+	program, err := Assemble(fmt.Sprintf(`
+        in [me]
+        jinz [me], #relay
+        out #1
+        out #0
+        out #0
+loop0:  in [x]
+        == [x], #-1, [t]
+        jinz [t], #loop0
+        in [y]
+        add [x], #1, [x]
+        out #1
+        out [x]
+        out #0
+        jinz #1, #loop0
+relay:  add [me], #1, [dest]
+        == [dest], #%d, [t]
+        jiz [t], #wait
+        add #255, #0, [dest]
+wait:   in [x]
+        == [x], #-1, [t]
+        jinz [t], #wait
+        in [y]
+        add [y], #1, [y]
+        out [dest]
+        out [x]
+        out [y]
+        jinz #1, #wait
+me:     .data 0
+dest:   .data 0
+x:      .data 0
+y:      .data 0
+t:      .data 0
+`, size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
+
+func TestNICNetworkNAT(t *testing.T) {
+	const size = 4
+	for name, mode := range map[string]ExecutionMode{"serial": Serial, "concurrent": Concurrent} {
+		t.Run(name, func(t *testing.T) {
+			n := NewNICNetwork(relayNIC(t, size), size)
+			nat := NewNAT(255)
+			n.Monitor = nat
+			if err := n.Run(mode); err != nil {
+				t.Fatal(err)
+			}
+			// The NAT re-sends the first packet to 0 when idle, and stops
+			// when the next one has the same y.
+			if want := (Packet{Source: size - 1, Dest: 255, X: 0, Y: size - 1}); nat.First == nil || *nat.First != want {
+				t.Errorf("first packet is %v, want %v", nat.First, want)
+			}
+			if want := (Packet{Source: size - 1, Dest: 255, X: 1, Y: size - 1}); nat.Last == nil || *nat.Last != want {
+				t.Errorf("last packet is %v, want %v", nat.Last, want)
+			}
+			if want := []int{size - 1}; !reflect.DeepEqual(nat.Sent, want) {
+				t.Errorf("NAT sent %v, want %v", nat.Sent, want)
+			}
+			stats := n.Stats()
+			if s := stats[0]; s.Sent != 2 || s.Received != 1 {
+				t.Errorf("NIC 0 sent %d and received %d, want 2 and 1", s.Sent, s.Received)
+			}
+			for addr := 1; addr < size; addr++ {
+				if s := stats[addr]; s.Sent != 2 || s.Received != 2 {
+					t.Errorf("NIC %d sent %d and received %d, want 2 and 2", addr, s.Sent, s.Received)
+				}
+			}
+		})
+	}
+}
+
+func TestNICNetworkStops(t *testing.T) {
+	for name, mode := range map[string]ExecutionMode{"serial": Serial, "concurrent": Concurrent} {
+		t.Run(name+"/idle without monitor", func(t *testing.T) {
+			n := NewNICNetwork(relayNIC(t, 3), 3)
+			if err := n.Run(mode); err != nil {
+				t.Fatal(err)
+			}
+			if n.Dropped != 1 {
+				t.Errorf("dropped %d packets, want 1", n.Dropped)
+			}
+		})
+		t.Run(name+"/all halted", func(t *testing.T) {
+			n := NewNICNetwork(ParseProgram("3,0,99"), 3)
+			n.Monitor = NewNAT(255)
+			if err := n.Run(mode); err != nil {
+				t.Fatal(err)
+			}
+		})
+		t.Run(name+"/nat without packets", func(t *testing.T) {
+			n := NewNICNetwork(ParseProgram("3,0,3,0,1105,1,2"), 2)
+			nat := NewNAT(255)
+			n.Monitor = nat
+			if err := n.Run(mode); err != nil {
+				t.Fatal(err)
+			}
+			if nat.Last != nil || len(nat.Sent) != 0 {
+				t.Errorf("NAT received %v and sent %v, want nothing", nat.Last, nat.Sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCheckedArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		opcode   InstructionType
+		a, b     int
+		want     int
+		overflow bool
+	}{
+		{"MaxInt+1", Add, math.MaxInt, 1, 0, true},
+		{"MinInt+-1", Add, math.MinInt, -1, 0, true},
+		{"MaxInt+MinInt", Add, math.MaxInt, math.MinInt, -1, false},
+		{"MinInt*-1", Mul, math.MinInt, -1, 0, true},
+		{"-1*MinInt", Mul, -1, math.MinInt, 0, true},
+		{"2^62*2", Mul, 1 << 62, 2, 0, true},
+		{"-2^62*2", Mul, -1 << 62, 2, math.MinInt, false},
+		{"3037000499^2", Mul, 3037000499, 3037000499, 9223372030926249001, false},
+		{"3037000500^2", Mul, 3037000500, 3037000500, 0, true},
+		{"0*MinInt", Mul, 0, math.MinInt, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// A no-op before the arithmetic, so that its address isn't 0.
+			program := fmt.Sprintf("1101,0,0,20,%d,%d,%d,20,4,20,99", 1100+int(test.opcode), test.a, test.b)
+			got, err := NewCheckedComputer(ParseProgram(program)).RunWith()
+			var overflow *OverflowError
+			if !test.overflow {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != 1 || got[0] != test.want {
+					t.Errorf("got %v, want [%d]", got, test.want)
+				}
+				return
+			}
+			if !errors.As(err, &overflow) {
+				t.Fatalf("got %v, want OverflowError", err)
+			}
+			if overflow.Address != 4 || overflow.Opcode != test.opcode || overflow.A != test.a || overflow.B != test.b {
+				t.Errorf("got %+v, want %v of %d, %d @ 4", overflow, test.opcode, test.a, test.b)
+			}
+		})
+	}
+}
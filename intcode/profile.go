@@ -0,0 +1,245 @@
+package intcode
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type BranchCount struct {
+	Taken    int
+	NotTaken int
+}
+
+// Profiler is a Tracer that counts how often each address and opcode is
+// executed, and how often conditional jumps are taken.
+type Profiler struct {
+	Counts       map[int]int
+	OpcodeCounts map[InstructionType]int
+	Branches     map[int]*BranchCount
+}
+
+func NewProfiler() *Profiler {
+	return &Profiler{
+		Counts:       make(map[int]int),
+		OpcodeCounts: make(map[InstructionType]int),
+		Branches:     make(map[int]*BranchCount),
+	}
+}
+
+func (p *Profiler) Trace(e *TraceEvent) {
+	p.Counts[e.Address]++
+	p.OpcodeCounts[e.Opcode]++
+	if e.Opcode != JumpIfZero && e.Opcode != JumpIfNonZero {
+		return
+	}
+	branch, ok := p.Branches[e.Address]
+	if !ok {
+		branch = new(BranchCount)
+		p.Branches[e.Address] = branch
+	}
+	if e.Next == e.Address+len(e.Words) {
+		branch.NotTaken++
+	} else {
+		branch.Taken++
+	}
+}
+
+// Coverage returns the fraction of instructions in a linear disassembly of
+// program that were executed.
+func (p *Profiler) Coverage(program []int) float64 {
+	var total, covered int
+	for addr := 0; addr < len(program); {
+		instr, ok := decodeAt(program, addr)
+		if !ok {
+			addr++
+			continue
+		}
+		total++
+		if p.Counts[addr] > 0 {
+			covered++
+		}
+		addr += instr.size()
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total)
+}
+
+func (p *Profiler) annotation(addr int) string {
+	count := p.Counts[addr]
+	if count == 0 {
+		return "never"
+	}
+	note := fmt.Sprintf("x%d", count)
+	if branch, ok := p.Branches[addr]; ok {
+		note += fmt.Sprintf(" taken=%d not-taken=%d", branch.Taken, branch.NotTaken)
+	}
+	return note
+}
+
+// Annotate writes a disassembly of program with execution counts in the
+// comment of each instruction. Executed addresses always start a new line.
+func (p *Profiler) Annotate(w io.Writer, program []int) error {
+	entries := make(map[int]bool, len(p.Counts))
+	for addr := range p.Counts {
+		entries[addr] = true
+	}
+	return disassemble(w, program, entries, p.annotation)
+}
+
+// WriteOpcodeCounts writes the number of executions per opcode, most
+// frequent first.
+func (p *Profiler) WriteOpcodeCounts(w io.Writer) error {
+	var opcodes []InstructionType
+	for opcode := range p.OpcodeCounts {
+		opcodes = append(opcodes, opcode)
+	}
+	sort.Slice(opcodes, func(i, j int) bool {
+		ci, cj := p.OpcodeCounts[opcodes[i]], p.OpcodeCounts[opcodes[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return opcodes[i] < opcodes[j]
+	})
+	for _, opcode := range opcodes {
+		if _, err := fmt.Fprintf(w, "%s\t%d\n", instructionNames[opcode], p.OpcodeCounts[opcode]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePprof writes the profile as a gzipped pprof protobuf, readable with
+// `go tool pprof`. Each executed address is a function named after its
+// instruction in program, with the address as line number.
+func (p *Profiler) WritePprof(w io.Writer, program []int) error {
+	var addrs []int
+	for addr := range p.Counts {
+		addrs = append(addrs, addr)
+	}
+	sort.Ints(addrs)
+
+	strs := newStringTable()
+	valueType := func(typ, unit string) protoBuffer {
+		var vt protoBuffer
+		vt.int(1, strs.index(typ))
+		vt.int(2, strs.index(unit))
+		return vt
+	}
+	var prof protoBuffer
+	prof.message(1, valueType("instructions", "count"))
+	for i, addr := range addrs {
+		id := i + 1
+		var sample protoBuffer
+		sample.packed(1, id)
+		sample.packed(2, p.Counts[addr])
+		prof.message(2, sample)
+	}
+	for i, addr := range addrs {
+		id := i + 1
+		var line protoBuffer
+		line.int(1, id)
+		line.int(2, addr)
+		var loc protoBuffer
+		loc.int(1, id)
+		loc.int(3, addr)
+		loc.message(4, line)
+		prof.message(4, loc)
+	}
+	for i, addr := range addrs {
+		id := i + 1
+		name := fmt.Sprintf("%d: ?", addr)
+		if instr, ok := decodeAt(program, addr); ok {
+			name = fmt.Sprintf("%d: %v", addr, instr)
+		} else if addr >= 0 && addr < len(program) {
+			name = fmt.Sprintf("%d: .data %d", addr, program[addr])
+		}
+		var fn protoBuffer
+		fn.int(1, id)
+		fn.int(2, strs.index(name))
+		fn.int(3, strs.index(name))
+		fn.int(4, strs.index("intcode"))
+		prof.message(5, fn)
+	}
+	prof.message(11, valueType("instructions", "count"))
+	prof.int(12, 1)
+	// The string table must be written last, after all strings were indexed.
+	for _, s := range strs.strs {
+		prof.bytes(6, []byte(s))
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(prof); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+type stringTable struct {
+	strs    []string
+	indices map[string]int
+}
+
+func newStringTable() *stringTable {
+	// By the pprof format, the first string must be empty.
+	return &stringTable{strs: []string{""}, indices: map[string]int{"": 0}}
+}
+
+func (t *stringTable) index(s string) int {
+	if i, ok := t.indices[s]; ok {
+		return i
+	}
+	t.indices[s] = len(t.strs)
+	t.strs = append(t.strs, s)
+	return t.indices[s]
+}
+
+// protoBuffer is a minimal protocol buffer encoder, supporting only the wire
+// types used by the pprof format.
+type protoBuffer []byte
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		*b = append(*b, byte(x)|0x80)
+		x >>= 7
+	}
+	*b = append(*b, byte(x))
+}
+
+func (b *protoBuffer) key(field, wireType int) {
+	b.varint(uint64(field<<3 | wireType))
+}
+
+func (b *protoBuffer) int(field, x int) {
+	if x == 0 {
+		return
+	}
+	b.key(field, wireVarint)
+	b.varint(uint64(x))
+}
+
+func (b *protoBuffer) bytes(field int, bs []byte) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(bs)))
+	*b = append(*b, bs...)
+}
+
+func (b *protoBuffer) message(field int, msg protoBuffer) {
+	b.bytes(field, msg)
+}
+
+func (b *protoBuffer) packed(field int, xs ...int) {
+	var inner protoBuffer
+	for _, x := range xs {
+		inner.varint(uint64(x))
+	}
+	b.bytes(field, inner)
+}
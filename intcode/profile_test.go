@@ -0,0 +1,76 @@
+package intcode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// countdown decrements the value at address 12 until it's zero, and outputs
+// it.
+const countdown = "1001,12,-1,12,1005,12,0,4,12,99,0,0,3"
+
+func profile(t *testing.T) (*Profiler, []int) {
+	t.Helper()
+	program := ParseProgram(countdown)
+	c := NewComputer(program)
+	p := NewProfiler()
+	c.Tracer = p
+	if _, err := c.RunWith(); err != nil {
+		t.Fatal(err)
+	}
+	return p, program
+}
+
+func TestProfilerCounts(t *testing.T) {
+	p, program := profile(t)
+	if want := map[int]int{0: 3, 4: 3, 7: 1, 9: 1}; !reflect.DeepEqual(p.Counts, want) {
+		t.Errorf("address counts are %v, want %v", p.Counts, want)
+	}
+	wantOpcodes := map[InstructionType]int{Add: 3, JumpIfNonZero: 3, Output: 1, Halt: 1}
+	if !reflect.DeepEqual(p.OpcodeCounts, wantOpcodes) {
+		t.Errorf("opcode counts are %v, want %v", p.OpcodeCounts, wantOpcodes)
+	}
+	if want := map[int]*BranchCount{4: {Taken: 2, NotTaken: 1}}; !reflect.DeepEqual(p.Branches, want) {
+		t.Errorf("branches are %v, want %v", p.Branches, want)
+	}
+	if got := p.Coverage(program); got != 1 {
+		t.Errorf("coverage is %v, want 1", got)
+	}
+}
+
+func TestWritePprof(t *testing.T) {
+	p, program := profile(t)
+	var buf bytes.Buffer
+	if err := p.WritePprof(&buf, program); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The golden file is checked to be readable with `go tool pprof -raw`.
+	golden := filepath.Join("testdata", "countdown.pb")
+	if *update {
+		if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("profile differs from %s:\ngot  %x\nwant %x", golden, got, want)
+	}
+}
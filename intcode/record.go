@@ -0,0 +1,75 @@
+package intcode
+
+import "fmt"
+
+// UndoRecord holds what is needed to revert an executed instruction: the
+// registers and instruction count before it, the old values of the memory it
+// wrote, and the values it consumed or produced.
+type UndoRecord struct {
+	Count              int
+	InstructionPointer int
+	RelativeBase       int
+	Writes             []MemoryWrite
+	Input              *int
+	Output             *int
+}
+
+func (r UndoRecord) String() string {
+	s := fmt.Sprintf("#%d @%d rb=%d", r.Count, r.InstructionPointer, r.RelativeBase)
+	for _, w := range r.Writes {
+		s += fmt.Sprintf(" [%d]: %d -> %d", w.Address, w.Old, w.New)
+	}
+	if r.Input != nil {
+		s += fmt.Sprintf(" in=%d", *r.Input)
+	}
+	if r.Output != nil {
+		s += fmt.Sprintf(" out=%d", *r.Output)
+	}
+	return s
+}
+
+// StartRecording makes the computer log an undo record for every executed
+// instruction, so that they can be reverted with Undo. Instructions are not
+// fused while recording, so that each one has its own record.
+func (c *Computer) StartRecording() {
+	c.recording = true
+}
+
+// StopRecording stops logging instructions and discards the undo log.
+func (c *Computer) StopRecording() {
+	c.recording = false
+	c.undoLog = nil
+}
+
+func (c *Computer) Recording() bool {
+	return c.recording
+}
+
+// UndoLog returns the records of the instructions executed while recording,
+// oldest first.
+func (c *Computer) UndoLog() []UndoRecord {
+	return c.undoLog
+}
+
+// Undo reverts the last recorded instruction, restoring the memory it wrote
+// and the registers and instruction count from before it. The record is
+// removed from the log and returned, so that the caller may feed its input
+// again when re-executing it.
+func (c *Computer) Undo() (UndoRecord, bool) {
+	if len(c.undoLog) == 0 {
+		return UndoRecord{}, false
+	}
+	r := c.undoLog[len(c.undoLog)-1]
+	c.undoLog = c.undoLog[:len(c.undoLog)-1]
+	for i := len(r.Writes) - 1; i >= 0; i-- {
+		w := r.Writes[i]
+		c.state[w.Address] = w.Old
+		if w.Address < len(c.cache) {
+			c.cache[w.Address].valid = false
+		}
+	}
+	c.instructionPointer = r.InstructionPointer
+	c.relativeBase = r.RelativeBase
+	c.instructionCount = r.Count
+	return r, true
+}
@@ -0,0 +1,128 @@
+package intcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+// doubler reads values and outputs their double, moving the relative base
+// and writing beyond the program on every iteration.
+const doubler = "109,3,203,20,22201,20,20,21,204,21,1101,7,8,100,109,-3,1105,1,0,99,0,0,0,0,0"
+
+type machineState struct {
+	Memory    []int
+	Registers Registers
+	Count     int
+}
+
+// state returns the computer state, ignoring memory grown beyond the
+// program if it's zero.
+func state(c *Computer, size int) machineState {
+	memory := c.Snapshot().Memory
+	for _, v := range memory[size:] {
+		if v != 0 {
+			size = len(memory)
+			break
+		}
+	}
+	return machineState{memory[:size], c.Registers(), c.InstructionCount()}
+}
+
+func TestStepBackRestoresState(t *testing.T) {
+	program := ParseProgram(doubler)
+	for _, n := range []int{1, 5, 7, 12, 20} {
+		c := NewComputer(program)
+		c.StartRecording()
+		out := &inout{}
+		d := NewDebugger(c, &inout{input: []int{1, 2, 3}}, out)
+		initial := state(c, len(program))
+		for i := 0; i < n; i++ {
+			if _, err := d.Step(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		forward, outputs := state(c, len(program)), append([]int(nil), out.output...)
+		for i := 0; i < n; i++ {
+			if stop, err := d.StepBack(); err != nil || stop.Reason != StopStep {
+				t.Fatalf("step back #%d: %v, %v", i+1, stop, err)
+			}
+		}
+		if got := state(c, len(program)); !reflect.DeepEqual(got, initial) {
+			t.Errorf("after %d steps back got %+v, want %+v", n, got, initial)
+		}
+		if stop, _ := d.StepBack(); stop.Reason != StopStart {
+			t.Errorf("stepped back before the start: %v", stop)
+		}
+		// Running forward again reads the same inputs and doesn't repeat
+		// outputs.
+		for i := 0; i < n; i++ {
+			if _, err := d.Step(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := state(c, len(program)); !reflect.DeepEqual(got, forward) {
+			t.Errorf("after %d steps again got %+v, want %+v", n, got, forward)
+		}
+		if !reflect.DeepEqual(out.output, outputs) {
+			t.Errorf("after %d steps again outputs are %v, want %v", n, out.output, outputs)
+		}
+	}
+}
+
+func TestContinueBackToStart(t *testing.T) {
+	program := ParseProgram(doubler)
+	c := NewComputer(program)
+	c.StartRecording()
+	initial := state(c, len(program))
+	d := NewDebugger(c, &inout{input: []int{1, 2, 3}}, &inout{})
+	d.SetBreakpoint(8)
+	for i := 0; i < 3; i++ {
+		if _, err := d.Continue(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stop, err := d.ContinueBack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Stop{Reason: StopBreakpoint, Address: 8}); stop != want || c.InstructionCount() != 10 {
+		t.Errorf("got %v at count %d, want %v at count 10", stop, c.InstructionCount(), want)
+	}
+	d.ClearBreakpoint(8)
+	if stop, err = d.ContinueBack(); err != nil || stop.Reason != StopStart {
+		t.Fatalf("got %v, %v, want start of recording", stop, err)
+	}
+	if got := state(c, len(program)); !reflect.DeepEqual(got, initial) {
+		t.Errorf("got %+v, want %+v", got, initial)
+	}
+}
+
+func TestUndoUntilStart(t *testing.T) {
+	program := ParseProgram(doubler)
+	c := NewComputer(program)
+	c.StartRecording()
+	initial := state(c, len(program))
+	s := &inout{input: []int{4, 5}}
+	if _, ok := c.Run(s, s).(*InputExhaustedError); !ok {
+		t.Fatal("want input exhausted")
+	}
+	if want := []int{8, 10}; !reflect.DeepEqual(s.output, want) {
+		t.Errorf("outputs are %v, want %v", s.output, want)
+	}
+	var inputs []int
+	for {
+		r, ok := c.Undo()
+		if !ok {
+			break
+		}
+		if r.Input != nil {
+			inputs = append([]int{*r.Input}, inputs...)
+		}
+	}
+	if want := []int{4, 5}; !reflect.DeepEqual(inputs, want) {
+		t.Errorf("undone inputs are %v, want %v", inputs, want)
+	}
+	if got := state(c, len(program)); !reflect.DeepEqual(got, initial) {
+		t.Errorf("got %+v, want %+v", got, initial)
+	}
+}
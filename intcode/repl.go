@@ -0,0 +1,257 @@
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const replHelp = `Commands:
+  s, step [n]          execute n instructions (default 1)
+  c, continue          run until breakpoint, watchpoint or halt
+  record               start recording, which allows running backwards
+  rs, rstep [n]        revert n instructions (default 1)
+  rc, rcontinue        run backwards until breakpoint, watchpoint or start
+  goto <count>         run forwards or backwards to an instruction count
+  b, break <addr>      set breakpoint at address
+  b, break <mnemonic>  set breakpoint on every instruction of a kind
+  d, delete <addr>     remove breakpoint at address
+  d, delete <mnemonic> remove breakpoint on instruction kind
+  w, watch <addr>      stop when the value at address changes
+  u, unwatch <addr>    remove watchpoint
+  r, regs              print instruction pointer and relative base
+  ip <addr>            set instruction pointer
+  rb <value>           set relative base
+  x <addr> [n]         print n memory cells starting at address
+  poke <addr> <value>  write value at address
+  l, list [addr] [n]   disassemble n instructions from address
+  detach               resume normal execution until the program ends
+  q, quit              exit the debugger
+  h, help              print this message
+`
+
+func parseInts(args []string) ([]int, error) {
+	ints := make([]int, len(args))
+	for i, arg := range args {
+		v, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number: %q", arg)
+		}
+		ints[i] = v
+	}
+	return ints, nil
+}
+
+func intArg(args []string, i, def int) (int, error) {
+	if i >= len(args) {
+		return def, nil
+	}
+	ints, err := parseInts(args[i : i+1])
+	if err != nil {
+		return 0, err
+	}
+	return ints[0], nil
+}
+
+type repl struct {
+	d *Debugger
+	w io.Writer
+}
+
+func (r *repl) printCurrent() {
+	ip := r.d.c.instructionPointer
+	text, _ := r.d.Instruction(ip)
+	fmt.Fprintf(r.w, "%d: %s\t(%v #%d)\n", ip, text, r.d.Registers(), r.d.c.instructionCount)
+}
+
+func (r *repl) report(stop Stop, err error) bool {
+	if err != nil {
+		fmt.Fprintf(r.w, "error: %v\n", err)
+		return false
+	}
+	if stop.Reason == StopHalt || stop.Reason == StopStart {
+		fmt.Fprintln(r.w, stop)
+		return false
+	}
+	if stop.Reason != StopStep {
+		fmt.Fprintln(r.w, stop)
+	}
+	r.printCurrent()
+	return true
+}
+
+func (r *repl) breakArg(arg string, set bool) error {
+	if opcode, ok := instructionsByName[arg]; ok {
+		if set {
+			r.d.SetOpcodeBreakpoint(opcode)
+		} else {
+			r.d.ClearOpcodeBreakpoint(opcode)
+		}
+		return nil
+	}
+	addr, err := intArg([]string{arg}, 0, 0)
+	if err != nil {
+		return err
+	}
+	if set {
+		r.d.SetBreakpoint(addr)
+	} else {
+		r.d.ClearBreakpoint(addr)
+	}
+	return nil
+}
+
+func (r *repl) exec(cmd string, args []string) (bool, error) {
+	switch cmd {
+	case "s", "step":
+		n, err := intArg(args, 0, 1)
+		if err != nil {
+			return true, err
+		}
+		for i := 0; i < n; i++ {
+			if !r.report(r.d.Step()) {
+				break
+			}
+		}
+	case "c", "continue":
+		r.report(r.d.Continue())
+	case "record":
+		r.d.c.StartRecording()
+	case "rs", "rstep":
+		n, err := intArg(args, 0, 1)
+		if err != nil {
+			return true, err
+		}
+		for i := 0; i < n; i++ {
+			if !r.report(r.d.StepBack()) {
+				break
+			}
+		}
+	case "rc", "rcontinue":
+		r.report(r.d.ContinueBack())
+	case "goto":
+		if len(args) != 1 {
+			return true, fmt.Errorf("%s expects one argument", cmd)
+		}
+		count, err := intArg(args, 0, 0)
+		if err != nil {
+			return true, err
+		}
+		r.report(r.d.RunTo(count))
+	case "b", "break", "d", "delete":
+		if len(args) != 1 {
+			return true, fmt.Errorf("%s expects one argument", cmd)
+		}
+		return true, r.breakArg(args[0], cmd == "b" || cmd == "break")
+	case "w", "watch", "u", "unwatch":
+		ints, err := parseInts(args)
+		if err != nil {
+			return true, err
+		}
+		if len(ints) != 1 {
+			return true, fmt.Errorf("%s expects one argument", cmd)
+		}
+		if cmd == "w" || cmd == "watch" {
+			r.d.SetWatchpoint(ints[0])
+		} else {
+			r.d.ClearWatchpoint(ints[0])
+		}
+	case "r", "regs":
+		fmt.Fprintln(r.w, r.d.Registers())
+	case "ip", "rb":
+		ints, err := parseInts(args)
+		if err != nil {
+			return true, err
+		}
+		if len(ints) != 1 {
+			return true, fmt.Errorf("%s expects one argument", cmd)
+		}
+		regs := r.d.Registers()
+		if cmd == "ip" {
+			regs.InstructionPointer = ints[0]
+		} else {
+			regs.RelativeBase = ints[0]
+		}
+		r.d.SetRegisters(regs)
+	case "x":
+		addr, err := intArg(args, 0, r.d.c.instructionPointer)
+		if err != nil {
+			return true, err
+		}
+		n, err := intArg(args, 1, 1)
+		if err != nil {
+			return true, err
+		}
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(r.w, "[%d] %d\n", addr+i, r.d.Peek(addr+i))
+		}
+	case "poke":
+		ints, err := parseInts(args)
+		if err != nil {
+			return true, err
+		}
+		if len(ints) != 2 {
+			return true, fmt.Errorf("%s expects two arguments", cmd)
+		}
+		return true, r.d.Poke(ints[0], ints[1])
+	case "l", "list":
+		addr, err := intArg(args, 0, r.d.c.instructionPointer)
+		if err != nil {
+			return true, err
+		}
+		n, err := intArg(args, 1, 10)
+		if err != nil {
+			return true, err
+		}
+		for i := 0; i < n; i++ {
+			text, size := r.d.Instruction(addr)
+			marker := " "
+			if r.d.breakpoints[addr] {
+				marker = "*"
+			}
+			fmt.Fprintf(r.w, "%s %d: %s\n", marker, addr, text)
+			addr += size
+		}
+	case "detach":
+		return false, r.d.Detach()
+	case "q", "quit":
+		return false, nil
+	case "h", "help":
+		fmt.Fprint(r.w, replHelp)
+	default:
+		return true, fmt.Errorf("Unknown command %q, type 'help' for a list", cmd)
+	}
+	return true, nil
+}
+
+// REPL reads debugger commands from r, one per line, writing results to w.
+// An empty line repeats the previous command.
+func (d *Debugger) REPL(r io.Reader, w io.Writer) error {
+	session := &repl{d: d, w: w}
+	scanner := bufio.NewScanner(r)
+	session.printCurrent()
+	var last []string
+	for {
+		fmt.Fprint(w, "(intcode) ")
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			fields = last
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		last = fields
+		more, err := session.exec(fields[0], fields[1:])
+		if err != nil {
+			fmt.Fprintf(w, "error: %v\n", err)
+		}
+		if !more {
+			return nil
+		}
+	}
+}
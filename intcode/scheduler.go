@@ -0,0 +1,220 @@
+package intcode
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// BlockedMachine describes a computer waiting for input that will never come.
+// Channel is the name of the channel it's blocked on, if known.
+type BlockedMachine struct {
+	Name      string
+	Registers Registers
+	Channel   string
+}
+
+func (m BlockedMachine) String() string {
+	if m.Channel == "" {
+		return fmt.Sprintf("%s (%v)", m.Name, m.Registers)
+	}
+	return fmt.Sprintf("%s (%v) on %s", m.Name, m.Registers, m.Channel)
+}
+
+// DeadlockError is returned when every computer that hasn't finished is
+// waiting for input.
+type DeadlockError struct {
+	Blocked []BlockedMachine
+}
+
+func (e *DeadlockError) Error() string {
+	descs := make([]string, len(e.Blocked))
+	for i, m := range e.Blocked {
+		descs[i] = m.String()
+	}
+	return "Deadlock: all machines waiting for input: " + strings.Join(descs, ", ")
+}
+
+// Scheduler runs computers concurrently, connected by channels it manages,
+// and detects when none of them can make progress.
+type Scheduler struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	machines   []*machine
+	running    int
+	deadlocked bool
+	blocked    []BlockedMachine
+}
+
+type machine struct {
+	name      string
+	computer  *Computer
+	in        IntReader
+	out       IntWriter
+	waitingOn *Channel
+	err       error
+}
+
+func NewScheduler() *Scheduler {
+	s := &Scheduler{}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Channel is an unbounded queue of values between computers of a scheduler.
+// Reads block while the channel is empty, until a value is written, the
+// channel is closed or the scheduler finds a deadlock.
+type Channel struct {
+	s      *Scheduler
+	name   string
+	values []int
+	closed bool
+}
+
+func (s *Scheduler) NewChannel(name string, values ...int) *Channel {
+	return &Channel{s: s, name: name, values: append([]int(nil), values...)}
+}
+
+func (ch *Channel) Name() string {
+	return ch.name
+}
+
+func (ch *Channel) PushInt(i int) {
+	ch.s.mu.Lock()
+	defer ch.s.mu.Unlock()
+	if ch.closed {
+		return
+	}
+	ch.values = append(ch.values, i)
+	ch.s.cond.Broadcast()
+}
+
+// NextInt reads from the channel without the scheduler knowing who's
+// waiting. Computers added to the scheduler read through a wrapper that tracks it.
+func (ch *Channel) NextInt() (int, bool) {
+	return ch.read(nil)
+}
+
+// Close makes reads fail after the channel is drained. Values written after
+// Close are discarded.
+func (ch *Channel) Close() {
+	ch.s.mu.Lock()
+	defer ch.s.mu.Unlock()
+	ch.closed = true
+	ch.s.cond.Broadcast()
+}
+
+// Err returns io.EOF if the channel was closed.
+func (ch *Channel) Err() error {
+	ch.s.mu.Lock()
+	defer ch.s.mu.Unlock()
+	if ch.closed {
+		return io.EOF
+	}
+	return nil
+}
+
+func (ch *Channel) read(m *machine) (int, bool) {
+	s := ch.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m != nil {
+		m.waitingOn = ch
+		defer func() { m.waitingOn = nil }()
+	}
+	for len(ch.values) == 0 {
+		if s.deadlocked || ch.closed {
+			return 0, false
+		}
+		if m != nil {
+			s.checkDeadlock()
+		}
+		if !s.deadlocked {
+			s.cond.Wait()
+		}
+	}
+	v := ch.values[0]
+	ch.values = ch.values[1:]
+	return v, true
+}
+
+type machineInput struct {
+	m  *machine
+	ch *Channel
+}
+
+func (in machineInput) NextInt() (int, bool) {
+	return in.ch.read(in.m)
+}
+
+func (in machineInput) Err() error {
+	return in.ch.Err()
+}
+
+// checkDeadlock marks the scheduler as deadlocked if every running machine is
+// waiting on an empty channel. A waiting machine may have been signaled but
+// not yet woken up, so the channel contents must be checked. Once deadlocked,
+// the blocked machines are kept as found, since they stop waiting as they
+// exit. Must be called with the lock held.
+func (s *Scheduler) checkDeadlock() {
+	if s.deadlocked {
+		return
+	}
+	var blocked []BlockedMachine
+	for _, m := range s.machines {
+		if m.waitingOn != nil && len(m.waitingOn.values) == 0 && !m.waitingOn.closed {
+			blocked = append(blocked, BlockedMachine{
+				Name:      m.name,
+				Registers: m.computer.Registers(),
+				Channel:   m.waitingOn.name,
+			})
+		}
+	}
+	if s.running > 0 && len(blocked) == s.running {
+		s.deadlocked = true
+		s.blocked = blocked
+		s.cond.Broadcast()
+	}
+}
+
+// Add registers a computer to be run by Run. Deadlocks can only be detected
+// if in is a channel of this scheduler, or a reader that never blocks.
+func (s *Scheduler) Add(name string, c *Computer, in IntReader, out IntWriter) {
+	m := &machine{name: name, computer: c, in: in, out: out}
+	if ch, ok := in.(*Channel); ok && ch.s == s {
+		m.in = machineInput{m, ch}
+	}
+	s.machines = append(s.machines, m)
+}
+
+// Run executes all computers concurrently until they finish, returning their
+// errors by name. If they finished because of a deadlock, a DeadlockError
+// listing the blocked computers is also returned.
+func (s *Scheduler) Run() (map[string]error, error) {
+	var wg sync.WaitGroup
+	s.mu.Lock()
+	s.running = len(s.machines)
+	s.mu.Unlock()
+	for _, m := range s.machines {
+		wg.Add(1)
+		go func(m *machine) {
+			defer wg.Done()
+			err := m.computer.Run(m.in, m.out)
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			m.err = err
+			s.running--
+			s.checkDeadlock()
+		}(m)
+	}
+	wg.Wait()
+	errs := make(map[string]error, len(s.machines))
+	for _, m := range s.machines {
+		errs[m.name] = m.err
+	}
+	if !s.deadlocked {
+		return errs, nil
+	}
+	return errs, &DeadlockError{s.blocked}
+}
@@ -0,0 +1,44 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// echoLoop reads a value and writes it back forever.
+const echoLoop = "3,10,4,10,1105,1,0"
+
+func TestDeadlockReportsAllMachines(t *testing.T) {
+	for _, size := range []int{2, 5} {
+		for mode, modeName := range map[ExecutionMode]string{Serial: "serial", Concurrent: "concurrent"} {
+			t.Run(fmt.Sprintf("%s/%d", modeName, size), func(t *testing.T) {
+				n := NewNetwork()
+				names := []string{"A", "B", "C", "D", "E"}[:size]
+				for _, name := range names {
+					if err := n.AddNode(name, ParseProgram(echoLoop)); err != nil {
+						t.Fatal(err)
+					}
+				}
+				for i, name := range names {
+					if err := n.Connect(name, names[(i+1)%size]); err != nil {
+						t.Fatal(err)
+					}
+				}
+				_, err := n.Run(mode)
+				var deadlock *DeadlockError
+				if !errors.As(err, &deadlock) {
+					t.Fatalf("got %v, want a DeadlockError", err)
+				}
+				if len(deadlock.Blocked) != size {
+					t.Fatalf("got %v, want all %d machines blocked", err, size)
+				}
+				for i, m := range deadlock.Blocked {
+					if m.Name != names[i] || m.Registers.InstructionPointer != 0 || m.Channel != "input of "+names[i] {
+						t.Errorf("blocked[%d] = %v", i, m)
+					}
+				}
+			})
+		}
+	}
+}
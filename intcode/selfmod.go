@@ -0,0 +1,104 @@
+package intcode
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// CodeWrite is a write into an address that was executed or decoded as part
+// of an instruction.
+type CodeWrite struct {
+	// Count is the instruction count of the writer.
+	Count int
+	// Writer is the address of the instruction doing the write.
+	Writer      int
+	Instruction string
+	Address     int
+	Old, New    int
+}
+
+func (w CodeWrite) String() string {
+	return fmt.Sprintf("@%d: %s wrote [%d]: %d -> %d", w.Writer, w.Instruction, w.Address, w.Old, w.New)
+}
+
+// SelfModDetector is a Tracer that records writes into code. Code are the
+// words of every executed instruction, plus those of the static CFG if one was
+// given. Writes that don't change the value are also recorded, since they
+// may still mean the program treats the address as data.
+type SelfModDetector struct {
+	code   map[int]bool
+	Writes []CodeWrite
+}
+
+// NewSelfModDetector creates a detector that considers as code the
+// instructions reachable in g, which may be nil.
+func NewSelfModDetector(g *CFG) *SelfModDetector {
+	d := &SelfModDetector{code: make(map[int]bool)}
+	if g != nil {
+		for addr := range g.code {
+			d.code[addr] = true
+		}
+	}
+	return d
+}
+
+func (d *SelfModDetector) Trace(e *TraceEvent) {
+	for i := range e.Words {
+		d.code[e.Address+i] = true
+	}
+	if len(e.Writes) == 0 {
+		return
+	}
+	desc := e.Mnemonic
+	if instr, ok := decodeAt(e.Words, 0); ok {
+		desc = instr.String()
+	}
+	for _, w := range e.Writes {
+		if !d.code[w.Address] {
+			continue
+		}
+		d.Writes = append(d.Writes, CodeWrite{
+			Count:       e.Count,
+			Writer:      e.Address,
+			Instruction: desc,
+			Address:     w.Address,
+			Old:         w.Old,
+			New:         w.New,
+		})
+	}
+}
+
+// Modified returns the sorted addresses of code that were written to.
+func (d *SelfModDetector) Modified() []int {
+	seen := make(map[int]bool)
+	var addrs []int
+	for _, w := range d.Writes {
+		if !seen[w.Address] {
+			seen[w.Address] = true
+			addrs = append(addrs, w.Address)
+		}
+	}
+	sort.Ints(addrs)
+	return addrs
+}
+
+// Report writes the modified addresses, each followed by the writes to it
+// in execution order.
+func (d *SelfModDetector) Report(w io.Writer) error {
+	byAddr := make(map[int][]CodeWrite)
+	for _, cw := range d.Writes {
+		byAddr[cw.Address] = append(byAddr[cw.Address], cw)
+	}
+	for _, addr := range d.Modified() {
+		if _, err := fmt.Fprintf(w, "[%d]: %d writes\n", addr, len(byAddr[addr])); err != nil {
+			return err
+		}
+		for _, cw := range byAddr[addr] {
+			if _, err := fmt.Fprintf(w, "\t#%d %v\n", cw.Count, cw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
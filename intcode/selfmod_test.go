@@ -0,0 +1,65 @@
+package intcode
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// patcher overwrites the last param of the instruction at 4 before running
+// it, making it write over the instruction at 0, and then increments the
+// first param of the instruction at 4.
+const patcher = "1101,3,0,7,1101,1,1,20,1001,5,1,5,99"
+
+func TestSelfModDetector(t *testing.T) {
+	program := ParseProgram(patcher)
+	tests := []struct {
+		name     string
+		cfg      *CFG
+		want     []CodeWrite
+		modified []int
+	}{
+		{"executed code", nil, []CodeWrite{
+			{Count: 1, Writer: 4, Instruction: "add #1, #1, [3]", Address: 3, Old: 7, New: 2},
+			{Count: 2, Writer: 8, Instruction: "add [5], #1, [5]", Address: 5, Old: 1, New: 2},
+		}, []int{3, 5}},
+		{"static code", BuildCFG(program), []CodeWrite{
+			{Count: 0, Writer: 0, Instruction: "add #3, #0, [7]", Address: 7, Old: 20, New: 3},
+			{Count: 1, Writer: 4, Instruction: "add #1, #1, [3]", Address: 3, Old: 7, New: 2},
+			{Count: 2, Writer: 8, Instruction: "add [5], #1, [5]", Address: 5, Old: 1, New: 2},
+		}, []int{3, 5, 7}},
+	}
+	for _, test := range tests {
+		d := NewSelfModDetector(test.cfg)
+		c := NewComputer(program)
+		c.Tracer = d
+		if _, err := c.RunWith(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(d.Writes, test.want) {
+			t.Errorf("%s: got writes %v, want %v", test.name, d.Writes, test.want)
+		}
+		if got := d.Modified(); !reflect.DeepEqual(got, test.modified) {
+			t.Errorf("%s: got modified %v, want %v", test.name, got, test.modified)
+		}
+	}
+}
+
+func TestSelfModDetectorReport(t *testing.T) {
+	c := NewComputer(ParseProgram("1001,1,1,1,1001,1,1,1,99"))
+	d := NewSelfModDetector(nil)
+	c.Tracer = d
+	if _, err := c.RunWith(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := d.Report(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "[1]: 2 writes\n" +
+		"\t#0 @0: add [1], #1, [1] wrote [1]: 1 -> 2\n" +
+		"\t#1 @4: add [1], #1, [1] wrote [1]: 2 -> 3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
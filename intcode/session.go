@@ -0,0 +1,281 @@
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Session plays an ASCII program interactively, sending each line typed by
+// the user as input. Lines starting with "/" are session commands, and lines
+// starting with "!" recall commands from the history. Only the lines sent to
+// the program are written to Record, so that replaying a session doesn't
+// repeat commands like /save. When a snapshot is saved or restored, Record
+// restarts with a /restore of it, so that replaying it reaches the same state:
+//
+//	!!              repeat the last command
+//	!n              repeat the n-th command of the history
+//	!prefix         repeat the last command starting with prefix
+//	/history        list the commands sent so far
+//	/save <file>    save the computer state to file
+//	/restore <file> restore the computer state from file
+//	/quit           stop the session
+type Session struct {
+	c       *Computer
+	history []string
+	pending []string
+	// Record, if set, receives every line typed and sent to the program, so
+	// that the session can be replayed later. Replayed lines are not
+	// recorded again.
+	Record RecordFile
+}
+
+// RecordFile is where a session is recorded, usually an *os.File.
+type RecordFile interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+}
+
+func NewSession(c *Computer) *Session {
+	return &Session{c: c}
+}
+
+// History returns the lines executed so far.
+func (s *Session) History() []string {
+	return s.history
+}
+
+// Replay queues the lines in r to be executed before reading from the user.
+// Session commands are skipped, except for /restore.
+func (s *Session) Replay(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && (!strings.HasPrefix(line, "/") || strings.HasPrefix(line, "/restore ")) {
+			s.pending = append(s.pending, line)
+		}
+	}
+	return scanner.Err()
+}
+
+// runUntilInput writes the program output to w until it requires input,
+// returning whether it halted instead.
+func (s *Session) runUntilInput(w io.Writer) (bool, error) {
+	for {
+		status, err := s.c.RunUntil()
+		if err != nil {
+			return true, err
+		}
+		switch status {
+		case NeedsInput:
+			return false, nil
+		case Halted:
+			return true, nil
+		}
+		v, _ := s.c.Output()
+		if v < 0 || v > maxASCII {
+			fmt.Fprintf(w, "[%d]\n", v)
+		} else {
+			w.Write([]byte{byte(v)})
+		}
+	}
+}
+
+// recall expands a history reference.
+func (s *Session) recall(line string) (string, error) {
+	ref := line[1:]
+	if len(s.history) == 0 {
+		return "", fmt.Errorf("History is empty")
+	}
+	if ref == "!" {
+		return s.history[len(s.history)-1], nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(s.history) {
+			return "", fmt.Errorf("No command #%d in history", n)
+		}
+		return s.history[n-1], nil
+	}
+	for i := len(s.history) - 1; i >= 0; i-- {
+		if strings.HasPrefix(s.history[i], ref) {
+			return s.history[i], nil
+		}
+	}
+	return "", fmt.Errorf("No command starting with %q in history", ref)
+}
+
+// command executes a session command, returning whether the session should
+// stop. Replayed commands don't restart the record.
+func (s *Session) command(line string, w io.Writer, replayed bool) (bool, error) {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "/quit":
+		return true, nil
+	case "/history":
+		for i, cmd := range s.history {
+			fmt.Fprintf(w, "%4d  %s\n", i+1, cmd)
+		}
+	case "/save":
+		if len(fields) != 2 {
+			return false, fmt.Errorf("Usage: /save <file>")
+		}
+		f, err := os.Create(fields[1])
+		if err != nil {
+			return false, err
+		}
+		if err := s.c.Snapshot().Save(f); err != nil {
+			f.Close()
+			return false, err
+		}
+		if err := f.Close(); err != nil {
+			return false, err
+		}
+		if !replayed {
+			if err := s.restartRecord(fields[1]); err != nil {
+				return false, err
+			}
+		}
+		fmt.Fprintf(w, "Saved to %s\n", fields[1])
+	case "/restore":
+		if len(fields) != 2 {
+			return false, fmt.Errorf("Usage: /restore <file>")
+		}
+		if err := s.restore(fields[1], !replayed); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(w, "Restored from %s\n", fields[1])
+	default:
+		return false, fmt.Errorf("Unknown command %q", fields[0])
+	}
+	return false, nil
+}
+
+// Restore replaces the computer state with the snapshot saved at path, and
+// restarts Record from it.
+func (s *Session) Restore(path string) error {
+	return s.restore(path, true)
+}
+
+func (s *Session) restore(path string, restart bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	snapshot, err := LoadSnapshot(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	s.c.Restore(snapshot)
+	if restart {
+		return s.restartRecord(path)
+	}
+	return nil
+}
+
+// restartRecord truncates Record to a /restore of the snapshot at path,
+// since the lines before it are not needed to reach the current state.
+func (s *Session) restartRecord(path string) error {
+	if s.Record == nil {
+		return nil
+	}
+	if err := s.Record.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.Record.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(s.Record, "/restore %s\n", path)
+	return err
+}
+
+// record adds line to the history, and writes it to Record if persist is set.
+func (s *Session) record(line string, persist bool) {
+	s.history = append(s.history, line)
+	if persist && s.Record != nil {
+		fmt.Fprintln(s.Record, line)
+	}
+}
+
+// LineReader reads lines of input without their terminator, returning io.EOF
+// when there are no more.
+type LineReader interface {
+	ReadLine() (string, error)
+}
+
+type scannerLines struct {
+	scanner *bufio.Scanner
+}
+
+func (l scannerLines) ReadLine() (string, error) {
+	if !l.scanner.Scan() {
+		if err := l.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return l.scanner.Text(), nil
+}
+
+// Play runs the program, reading lines from r and writing the program output
+// to w, until the program halts, r is exhausted or the user quits. Errors in
+// history references and session commands are reported to w without stopping.
+func (s *Session) Play(r io.Reader, w io.Writer) error {
+	return s.PlayLines(scannerLines{bufio.NewScanner(r)}, w)
+}
+
+// PlayLines is like Play, reading lines with a LineReader, e.g. a line
+// editor.
+func (s *Session) PlayLines(lines LineReader, w io.Writer) error {
+	for {
+		halted, err := s.runUntilInput(w)
+		if err != nil || halted {
+			return err
+		}
+		var line string
+		replayed := len(s.pending) > 0
+		if replayed {
+			line = s.pending[0]
+			s.pending = s.pending[1:]
+			fmt.Fprintln(w, line)
+		} else {
+			line, err = lines.ReadLine()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			line = strings.TrimSpace(line)
+		}
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			if line, err = s.recall(line); err != nil {
+				fmt.Fprintln(w, err)
+				continue
+			}
+			fmt.Fprintln(w, line)
+		}
+		if strings.HasPrefix(line, "/") {
+			quit, err := s.command(line, w, replayed)
+			if err != nil {
+				fmt.Fprintln(w, err)
+			} else if line != "/history" && !quit {
+				s.record(line, false)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+		s.record(line, !replayed)
+		for _, ch := range []byte(line + "\n") {
+			s.c.AddInput(int(ch))
+		}
+	}
+}
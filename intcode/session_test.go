@@ -0,0 +1,89 @@
+package intcode
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tempRecord returns a record file in a temporary directory, which is
+// removed by the returned function.
+func tempRecord(t *testing.T) (string, *os.File, func()) {
+	dir, err := ioutil.TempDir("", "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "history"), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return dir, f, func() {
+		f.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func readRecord(t *testing.T, f *os.File) string {
+	data, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSessionRecordsOnlyTypedLines(t *testing.T) {
+	_, f, cleanup := tempRecord(t)
+	defer cleanup()
+	s := NewSession(NewComputer(ParseProgram(echoLoop)))
+	if err := s.Replay(strings.NewReader("north\n/history\n/quit\nsouth\n")); err != nil {
+		t.Fatal(err)
+	}
+	s.Record = f
+	if err := s.Play(strings.NewReader("east\n/history\n!n\n"), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readRecord(t, f), "east\nnorth\n"; got != want {
+		t.Errorf("recorded %q, want %q", got, want)
+	}
+	want := []string{"north", "south", "east", "north"}
+	if got := s.History(); !reflect.DeepEqual(got, want) {
+		t.Errorf("history is %q, want %q", got, want)
+	}
+}
+
+func TestSessionRecordRestartsAtSnapshot(t *testing.T) {
+	dir, f, cleanup := tempRecord(t)
+	defer cleanup()
+	save := filepath.Join(dir, "state.json")
+	c := NewComputer(ParseProgram(echoLoop))
+	s := NewSession(c)
+	s.Record = f
+	input := "ab\n/save " + save + "\ncd\n/restore " + save + "\nef\n"
+	if err := s.Play(strings.NewReader(input), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readRecord(t, f), "/restore "+save+"\nef\n"; got != want {
+		t.Fatalf("recorded %q, want %q", got, want)
+	}
+
+	replayed := NewComputer(ParseProgram(echoLoop))
+	s = NewSession(replayed)
+	record, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer record.Close()
+	if err := s.Replay(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Play(strings.NewReader(""), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := replayed.Snapshot(), c.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed state is %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package intcode
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Snapshot is a serializable copy of a computer's memory, registers, pending
+// input and output, and instruction count.
+type Snapshot struct {
+	Memory             []int `json:"memory"`
+	InstructionPointer int   `json:"ip"`
+	RelativeBase       int   `json:"rb"`
+	Input              []int `json:"input,omitempty"`
+	Output             []int `json:"output,omitempty"`
+	InstructionCount   int   `json:"count,omitempty"`
+}
+
+func (c *Computer) Snapshot() *Snapshot {
+	return &Snapshot{
+		Memory:             append([]int(nil), c.state...),
+		InstructionPointer: c.instructionPointer,
+		RelativeBase:       c.relativeBase,
+		Input:              append([]int(nil), c.input...),
+		Output:             append([]int(nil), c.output...),
+		InstructionCount:   c.instructionCount,
+	}
+}
+
+// Restore replaces the computer's state with the snapshot's. The undo log is
+// discarded, since it doesn't apply to the new state.
+func (c *Computer) Restore(s *Snapshot) {
+	c.state = append([]int(nil), s.Memory...)
+	c.cache = nil
+	c.undoLog = nil
+	c.instructionPointer = s.InstructionPointer
+	c.relativeBase = s.RelativeBase
+	c.input = append([]int(nil), s.Input...)
+	c.output = append([]int(nil), s.Output...)
+	c.instructionCount = s.InstructionCount
+}
+
+// Clone returns an independent copy of the computer, which can be run from
+// the same point without affecting the original.
+func (c *Computer) Clone() *Computer {
+	clone := *c
+	clone.state = append([]int(nil), c.state...)
+	clone.cache = nil
+	clone.undoLog = append([]UndoRecord(nil), c.undoLog...)
+	clone.input = append([]int(nil), c.input...)
+	clone.output = append([]int(nil), c.output...)
+	return &clone
+}
+
+func (s *Snapshot) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+func LoadSnapshot(r io.Reader) (*Snapshot, error) {
+	s := new(Snapshot)
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,54 @@
+package intcode
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// drain runs c until it needs input or halts, returning its outputs.
+func drain(t *testing.T, c *Computer) []int {
+	var outputs []int
+	for {
+		status, err := c.RunUntil()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for v, ok := c.Output(); ok; v, ok = c.Output() {
+			outputs = append(outputs, v)
+		}
+		if status != HasOutput {
+			return outputs
+		}
+	}
+}
+
+func TestRestoreReplacesQueuesAndCount(t *testing.T) {
+	program := ParseProgram(echoLoop)
+	c := NewComputer(program)
+	c.AddInput(1, 2, 3)
+	if _, err := c.RunUntil(); err != nil {
+		t.Fatal(err)
+	}
+	count := c.InstructionCount()
+	var b bytes.Buffer
+	if err := c.Snapshot().Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := LoadSnapshot(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := NewComputer(program)
+	other.AddInput(7, 8, 9)
+	drain(t, other)
+	other.AddInput(10)
+	other.Restore(snapshot)
+	if got := other.InstructionCount(); got != count {
+		t.Errorf("instruction count is %d, want %d", got, count)
+	}
+	if got, want := drain(t, other), []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs after restore are %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,308 @@
+package intcode
+
+import (
+	"math"
+	"sort"
+)
+
+type interval struct {
+	lo, hi int
+}
+
+func (i interval) fixed() bool {
+	return i.lo == i.hi
+}
+
+// linear is the expression sum(coefs[x] * x) + c.
+type linear struct {
+	coefs map[string]int
+	c     int
+}
+
+// linearize returns e as a linear expression of its symbols, if possible.
+func linearize(e *Expr) (linear, bool) {
+	switch e.Kind {
+	case ConstExpr:
+		return linear{c: e.Value}, true
+	case SymbolExpr:
+		return linear{coefs: map[string]int{e.Name: 1}}, true
+	case OpExpr:
+		a, okA := linearize(e.Args[0])
+		b, okB := linearize(e.Args[1])
+		if !okA || !okB {
+			return linear{}, false
+		}
+		switch e.Op {
+		case Add:
+			return a.plus(b, 1), true
+		case Mul:
+			if len(a.coefs) == 0 {
+				return b.scale(a.c), true
+			}
+			if len(b.coefs) == 0 {
+				return a.scale(b.c), true
+			}
+		}
+	}
+	return linear{}, false
+}
+
+// plus returns l + k*m.
+func (l linear) plus(m linear, k int) linear {
+	sum := linear{coefs: make(map[string]int), c: l.c + k*m.c}
+	for x, a := range l.coefs {
+		sum.coefs[x] = a
+	}
+	for x, a := range m.coefs {
+		if sum.coefs[x] += k * a; sum.coefs[x] == 0 {
+			delete(sum.coefs, x)
+		}
+	}
+	return sum
+}
+
+func (l linear) scale(k int) linear {
+	return linear{}.plus(l, k)
+}
+
+// bounds returns the range of values of l within the domains.
+func (l linear) bounds(domains map[string]interval) (lo, hi int) {
+	lo, hi = l.c, l.c
+	for x, a := range l.coefs {
+		d := domains[x]
+		if a > 0 {
+			lo, hi = lo+a*d.lo, hi+a*d.hi
+		} else {
+			lo, hi = lo+a*d.hi, hi+a*d.lo
+		}
+	}
+	return lo, hi
+}
+
+type relation int
+
+const (
+	relEq relation = iota // l == 0
+	relNe                 // l != 0
+	relLt                 // l < 0
+	relGe                 // l >= 0
+)
+
+// linearConstraint is a constraint "l rel 0". Constraints that are not linear
+// are only checked once all their symbols are fixed.
+type linearConstraint struct {
+	l        linear
+	rel      relation
+	opaque   *Constraint
+	symbols  []string
+	isLinear bool
+}
+
+func newLinearConstraint(c Constraint) linearConstraint {
+	e := c.Expr
+	if e.Kind == OpExpr && (e.Op == LessThan || e.Op == Equals) {
+		a, okA := linearize(e.Args[0])
+		b, okB := linearize(e.Args[1])
+		if okA && okB {
+			rels := map[InstructionType][2]relation{
+				// Indexed by NonZero.
+				LessThan: {relGe, relLt},
+				Equals:   {relNe, relEq},
+			}
+			rel := rels[e.Op][0]
+			if c.NonZero {
+				rel = rels[e.Op][1]
+			}
+			return linearConstraint{l: a.plus(b, -1), rel: rel, isLinear: true}
+		}
+	}
+	if l, ok := linearize(e); ok {
+		rel := relEq
+		if c.NonZero {
+			rel = relNe
+		}
+		return linearConstraint{l: l, rel: rel, isLinear: true}
+	}
+	symbols := make(map[string]bool)
+	e.symbols(symbols)
+	lc := linearConstraint{opaque: &c}
+	for x := range symbols {
+		lc.symbols = append(lc.symbols, x)
+	}
+	return lc
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int) int {
+	return -floorDiv(-a, b)
+}
+
+// narrow restricts the term a*x of a constraint to [lo, hi], returning
+// whether the domain of x changed.
+func narrow(domains map[string]interval, x string, a, lo, hi int) bool {
+	d := domains[x]
+	old := d
+	if a > 0 {
+		d.lo, d.hi = maxInt(d.lo, ceilDiv(lo, a)), minInt(d.hi, floorDiv(hi, a))
+	} else {
+		d.lo, d.hi = maxInt(d.lo, ceilDiv(hi, a)), minInt(d.hi, floorDiv(lo, a))
+	}
+	domains[x] = d
+	return d != old
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// propagate narrows the domains with each linear constraint until nothing
+// changes, returning false if a domain becomes empty or a constraint is
+// violated.
+func (lc linearConstraint) propagate(domains map[string]interval) (changed, ok bool) {
+	if !lc.isLinear {
+		env := make(map[string]int)
+		for _, x := range lc.symbols {
+			if !domains[x].fixed() {
+				return false, true
+			}
+			env[x] = domains[x].lo
+		}
+		return false, lc.opaque.holds(env)
+	}
+	lo, hi := lc.l.bounds(domains)
+	switch lc.rel {
+	case relEq:
+		if lo > 0 || hi < 0 {
+			return false, false
+		}
+	case relNe:
+		if lo == 0 && hi == 0 {
+			return false, false
+		}
+	case relLt:
+		if lo >= 0 {
+			return false, false
+		}
+	case relGe:
+		if hi < 0 {
+			return false, false
+		}
+	}
+	for x, a := range lc.l.coefs {
+		d := domains[x]
+		termLo, termHi := a*d.lo, a*d.hi
+		if a < 0 {
+			termLo, termHi = termHi, termLo
+		}
+		// Bounds of the other terms plus the constant.
+		restLo, restHi := lo-termLo, hi-termHi
+		switch lc.rel {
+		case relEq:
+			changed = narrow(domains, x, a, -restHi, -restLo) || changed
+		case relLt:
+			changed = narrow(domains, x, a, math.MinInt/2, -1-restLo) || changed
+		case relGe:
+			changed = narrow(domains, x, a, -restHi, math.MaxInt/2) || changed
+		case relNe:
+			if restLo != restHi || -restLo%a != 0 {
+				continue
+			}
+			v := -restLo / a
+			if v == d.lo {
+				d.lo++
+			} else if v == d.hi {
+				d.hi--
+			} else {
+				continue
+			}
+			domains[x] = d
+			changed = true
+		}
+		if d := domains[x]; d.lo > d.hi {
+			return changed, false
+		}
+	}
+	return changed, true
+}
+
+// maxPropagationRounds bounds propagation when domains shrink slowly, like
+// with x != y constraints.
+const maxPropagationRounds = 1000
+
+func propagateAll(domains map[string]interval, constraints []linearConstraint) bool {
+	for round := 0; round < maxPropagationRounds; round++ {
+		changed := false
+		for _, lc := range constraints {
+			c, ok := lc.propagate(domains)
+			if !ok {
+				return false
+			}
+			changed = changed || c
+		}
+		if !changed {
+			break
+		}
+	}
+	return true
+}
+
+// solve searches for an assignment of the symbols within their domains that
+// satisfies all constraints, splitting the smallest domain in halves.
+func solve(domains map[string]interval, constraints []linearConstraint) (map[string]int, bool) {
+	if !propagateAll(domains, constraints) {
+		return nil, false
+	}
+	var names []string
+	for x := range domains {
+		names = append(names, x)
+	}
+	sort.Strings(names)
+	split := ""
+	for _, x := range names {
+		d := domains[x]
+		if d.fixed() {
+			continue
+		}
+		if split == "" || d.hi-d.lo < domains[split].hi-domains[split].lo {
+			split = x
+		}
+	}
+	if split == "" {
+		// Propagation already checked every constraint with fixed symbols.
+		env := make(map[string]int)
+		for x, d := range domains {
+			env[x] = d.lo
+		}
+		return env, true
+	}
+	d := domains[split]
+	mid := d.lo + (d.hi-d.lo)/2
+	for _, half := range []interval{{d.lo, mid}, {mid + 1, d.hi}} {
+		sub := make(map[string]interval, len(domains))
+		for x, d := range domains {
+			sub[x] = d
+		}
+		sub[split] = half
+		if env, ok := solve(sub, constraints); ok {
+			return env, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,57 @@
+package intcode
+
+import "testing"
+
+func TestSolve(t *testing.T) {
+	x := &Expr{Kind: SymbolExpr, Name: "x"}
+	y := &Expr{Kind: SymbolExpr, Name: "y"}
+	eq := func(a, b *Expr) Constraint { return Assert(NewOp(Equals, a, b)) }
+	ne := func(a, b *Expr) Constraint { return Constraint{NewOp(Equals, a, b), false} }
+	lt := func(a, b *Expr) Constraint { return Assert(NewOp(LessThan, a, b)) }
+	ge := func(a, b *Expr) Constraint { return Constraint{NewOp(LessThan, a, b), false} }
+	add := func(a, b *Expr) *Expr { return NewOp(Add, a, b) }
+	mul := func(a, b *Expr) *Expr { return NewOp(Mul, a, b) }
+	k := NewConst
+	tests := []struct {
+		name        string
+		constraints []Constraint
+		feasible    bool
+	}{
+		{"equality", []Constraint{eq(add(mul(k(3), x), y), k(17)), eq(y, k(2))}, true},
+		{"linear combination", []Constraint{eq(add(mul(k(100), x), y), k(4321))}, true},
+		{"negative coefficient", []Constraint{eq(add(x, mul(k(-2), y)), k(5)), lt(k(40), x)}, true},
+		{"not equal", []Constraint{ne(x, k(0)), ne(x, k(1)), lt(x, k(3))}, true},
+		{"not equal symbols", []Constraint{ne(x, y), lt(x, k(1)), lt(y, k(1)), ge(y, k(0))}, false},
+		{"less than", []Constraint{lt(x, y), lt(y, k(2))}, true},
+		{"greater or equal", []Constraint{ge(x, k(99)), ge(y, x)}, true},
+		{"nonlinear", []Constraint{eq(mul(x, y), k(12)), lt(y, x)}, true},
+		{"infeasible equality", []Constraint{eq(mul(k(2), x), k(7))}, false},
+		{"infeasible bounds", []Constraint{lt(x, y), lt(y, x)}, false},
+		{"out of domain", []Constraint{eq(add(x, y), k(200))}, false},
+		{"contradiction", []Constraint{eq(x, k(3)), ne(x, k(3))}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			domains := map[string]interval{"x": {0, 99}, "y": {-5, 99}}
+			lcs := make([]linearConstraint, len(test.constraints))
+			for i, c := range test.constraints {
+				lcs[i] = newLinearConstraint(c)
+			}
+			env, ok := solve(domains, lcs)
+			if ok != test.feasible {
+				t.Fatalf("solve returned %v, want %v", ok, test.feasible)
+			}
+			if !ok {
+				return
+			}
+			if env["x"] < 0 || env["x"] > 99 || env["y"] < -5 || env["y"] > 99 {
+				t.Errorf("solution %v out of domain", env)
+			}
+			for _, c := range test.constraints {
+				if !c.holds(env) {
+					t.Errorf("solution %v violates %v", env, c)
+				}
+			}
+		})
+	}
+}
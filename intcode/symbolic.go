@@ -0,0 +1,591 @@
+package intcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ExprKind int
+
+const (
+	ConstExpr ExprKind = iota
+	SymbolExpr
+	// OpExpr applies Op to both Args.
+	OpExpr
+	// SelectExpr reads Memory at the address Args[0].
+	SelectExpr
+)
+
+// Expr is a symbolic value. Expressions are immutable and may be shared.
+type Expr struct {
+	Kind  ExprKind
+	Value int
+	Name  string
+	// Op is one of Add, Mul, LessThan or Equals.
+	Op   InstructionType
+	Args []*Expr
+	// Memory is the memory read by a SelectExpr, as it was when read. Nil
+	// cells are 0.
+	Memory []*Expr
+}
+
+func NewConst(v int) *Expr {
+	return &Expr{Kind: ConstExpr, Value: v}
+}
+
+var zeroExpr = NewConst(0)
+
+func boolExpr(b bool) *Expr {
+	if b {
+		return NewConst(1)
+	}
+	return zeroExpr
+}
+
+// NewOp returns the expression of an arithmetic or comparison instruction,
+// folding constants.
+func NewOp(op InstructionType, a, b *Expr) *Expr {
+	x, constA := a.IsConst()
+	y, constB := b.IsConst()
+	if constA && constB {
+		switch op {
+		case Add:
+			return NewConst(x + y)
+		case Mul:
+			return NewConst(x * y)
+		case LessThan:
+			return boolExpr(x < y)
+		case Equals:
+			return boolExpr(x == y)
+		}
+	}
+	switch {
+	case op == Add && constA && x == 0:
+		return b
+	case op == Add && constB && y == 0:
+		return a
+	case op == Mul && (constA && x == 0 || constB && y == 0):
+		return zeroExpr
+	case op == Mul && constA && x == 1:
+		return b
+	case op == Mul && constB && y == 1:
+		return a
+	case op == Equals && a == b:
+		return NewConst(1)
+	case op == LessThan && a == b:
+		return zeroExpr
+	}
+	return &Expr{Kind: OpExpr, Op: op, Args: []*Expr{a, b}}
+}
+
+// newSelect returns the value of memory at addr.
+func newSelect(memory []*Expr, addr *Expr) *Expr {
+	if v, ok := addr.IsConst(); ok && v >= 0 {
+		if v >= len(memory) || memory[v] == nil {
+			return zeroExpr
+		}
+		return memory[v]
+	}
+	return &Expr{Kind: SelectExpr, Args: []*Expr{addr}, Memory: memory}
+}
+
+func (e *Expr) IsConst() (int, bool) {
+	return e.Value, e.Kind == ConstExpr
+}
+
+// Eval returns the value of e with the symbols in env.
+func (e *Expr) Eval(env map[string]int) (int, error) {
+	switch e.Kind {
+	case ConstExpr:
+		return e.Value, nil
+	case SymbolExpr:
+		v, ok := env[e.Name]
+		if !ok {
+			return 0, fmt.Errorf("Unbound symbol %q", e.Name)
+		}
+		return v, nil
+	case SelectExpr:
+		addr, err := e.Args[0].Eval(env)
+		if err != nil {
+			return 0, err
+		}
+		if addr < 0 {
+			return 0, fmt.Errorf("Negative address %d in %v", addr, e)
+		}
+		if addr >= len(e.Memory) || e.Memory[addr] == nil {
+			return 0, nil
+		}
+		return e.Memory[addr].Eval(env)
+	}
+	a, err := e.Args[0].Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	b, err := e.Args[1].Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	v, _ := NewOp(e.Op, NewConst(a), NewConst(b)).IsConst()
+	return v, nil
+}
+
+// symbols adds the names of the symbols in e to set.
+func (e *Expr) symbols(set map[string]bool) {
+	switch e.Kind {
+	case SymbolExpr:
+		set[e.Name] = true
+	case SelectExpr:
+		for _, cell := range e.Memory {
+			if cell != nil && cell.Kind != ConstExpr {
+				cell.symbols(set)
+			}
+		}
+	}
+	for _, arg := range e.Args {
+		arg.symbols(set)
+	}
+}
+
+var exprOperators = map[InstructionType]string{
+	Add:      "+",
+	Mul:      "*",
+	LessThan: "<",
+	Equals:   "==",
+}
+
+func (e *Expr) String() string {
+	switch e.Kind {
+	case ConstExpr:
+		return fmt.Sprint(e.Value)
+	case SymbolExpr:
+		return e.Name
+	case SelectExpr:
+		return fmt.Sprintf("mem[%v]", e.Args[0])
+	}
+	return fmt.Sprintf("(%v %s %v)", e.Args[0], exprOperators[e.Op], e.Args[1])
+}
+
+// Constraint requires Expr to be non-zero, or zero if NonZero is false.
+type Constraint struct {
+	Expr    *Expr
+	NonZero bool
+}
+
+// Assert returns the constraint that e is non-zero, e.g. that a comparison
+// holds.
+func Assert(e *Expr) Constraint {
+	return Constraint{e, true}
+}
+
+func (c Constraint) String() string {
+	isComparison := c.Expr.Kind == OpExpr && (c.Expr.Op == LessThan || c.Expr.Op == Equals)
+	switch {
+	case isComparison && c.NonZero:
+		return c.Expr.String()
+	case isComparison:
+		return fmt.Sprintf("!%v", c.Expr)
+	case c.NonZero:
+		return fmt.Sprintf("%v != 0", c.Expr)
+	}
+	return fmt.Sprintf("%v == 0", c.Expr)
+}
+
+func (c Constraint) holds(env map[string]int) bool {
+	v, err := c.Expr.Eval(env)
+	return err == nil && (v != 0) == c.NonZero
+}
+
+// Path is a possible execution of a symbolic program, with the constraints
+// on symbols under which it's taken.
+type Path struct {
+	Constraints []Constraint
+	Outputs     []*Expr
+	// Status is Halted, or NeedsInput if the path ran out of input. If Err is
+	// set, the path stopped at an instruction that couldn't be executed.
+	Status Status
+	Err    error
+	// Steps is the number of instructions executed.
+	Steps  int
+	s      *Symbolic
+	memory []*Expr
+	// shared is true if memory may be referenced by other paths or
+	// expressions, so that it must be copied before writing.
+	shared bool
+	ip, rb int
+	inputs int
+	done   bool
+}
+
+// Memory returns the value at addr at the end of the path.
+func (p *Path) Memory(addr int) *Expr {
+	return newSelect(p.memory, NewConst(addr))
+}
+
+// Registers returns where the path stopped.
+func (p *Path) Registers() Registers {
+	return Registers{InstructionPointer: p.ip, RelativeBase: p.rb}
+}
+
+// Solve returns values for the symbols under which the path is taken and
+// the extra constraints hold.
+func (p *Path) Solve(extra ...Constraint) (map[string]int, bool) {
+	constraints := append(append([]Constraint(nil), p.Constraints...), extra...)
+	return p.s.Solve(constraints...)
+}
+
+func (p *Path) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v @ %d after %d steps", p.Status, p.ip, p.Steps)
+	if p.Err != nil {
+		fmt.Fprintf(&b, ": %v", p.Err)
+	}
+	for _, c := range p.Constraints {
+		fmt.Fprintf(&b, "\n\tif %v", c)
+	}
+	for _, out := range p.Outputs {
+		fmt.Fprintf(&b, "\n\tout %v", out)
+	}
+	return b.String()
+}
+
+func (p *Path) fork() *Path {
+	q := *p
+	q.Constraints = append([]Constraint(nil), p.Constraints...)
+	q.Outputs = append([]*Expr(nil), p.Outputs...)
+	p.shared, q.shared = true, true
+	return &q
+}
+
+func (p *Path) fail(err error) {
+	p.Status, p.Err, p.done = Halted, err, true
+}
+
+func (p *Path) load(addr int) *Expr {
+	if addr < len(p.memory) && p.memory[addr] != nil {
+		return p.memory[addr]
+	}
+	return zeroExpr
+}
+
+func (p *Path) store(addr int, v *Expr) {
+	if p.shared {
+		p.memory = append([]*Expr(nil), p.memory...)
+		p.shared = false
+	}
+	if addr >= len(p.memory) {
+		p.memory = append(p.memory, make([]*Expr, addr+1-len(p.memory))...)
+	}
+	p.memory[addr] = v
+}
+
+// read returns the value at a possibly symbolic address.
+func (p *Path) read(addr *Expr) *Expr {
+	if _, ok := addr.IsConst(); !ok {
+		p.shared = true
+	}
+	return newSelect(p.memory, addr)
+}
+
+// DefaultMaxPaths and DefaultMaxSteps are the limits of symbolic execution
+// when MaxPaths and MaxSteps are not set.
+const (
+	DefaultMaxPaths = 1 << 10
+	DefaultMaxSteps = 1 << 16
+)
+
+// maxConcretize is the number of values a symbolic address may take before
+// giving up on forking a path for each of them.
+const maxConcretize = 64
+
+// Symbolic executes a program with symbols in place of some memory cells and
+// inputs, forking a path at every conditional jump whose condition depends on
+// them. Each path collects the constraints under which it's taken, which can
+// be solved for the values of the symbols.
+//
+// Symbols have bounded integer domains. Addresses that depend on symbols are
+// read with SelectExpr expressions, but writes and jumps to them fork a path
+// for each possible address, as long as there are few of them. The solver
+// only reasons about linear expressions, other constraints are checked once
+// all their symbols are fixed.
+type Symbolic struct {
+	program []int
+	memory  []*Expr
+	inputs  []*Expr
+	domains map[string]interval
+	// MaxPaths limits the number of paths explored.
+	MaxPaths int
+	// MaxSteps limits the number of instructions executed in a path.
+	MaxSteps int
+}
+
+func NewSymbolic(program []int) *Symbolic {
+	s := &Symbolic{
+		program: program,
+		memory:  make([]*Expr, len(program)),
+		domains: make(map[string]interval),
+	}
+	for i, v := range program {
+		s.memory[i] = NewConst(v)
+	}
+	return s
+}
+
+// Symbol creates a symbol taking values from lo to hi, inclusive.
+func (s *Symbolic) Symbol(name string, lo, hi int) *Expr {
+	s.domains[name] = interval{lo, hi}
+	return &Expr{Kind: SymbolExpr, Name: name}
+}
+
+// SetMemory replaces the initial value at addr.
+func (s *Symbolic) SetMemory(addr int, e *Expr) {
+	if addr >= len(s.memory) {
+		s.memory = append(s.memory, make([]*Expr, addr+1-len(s.memory))...)
+	}
+	s.memory[addr] = e
+}
+
+// AddInput queues values to be consumed by input instructions.
+func (s *Symbolic) AddInput(values ...*Expr) {
+	s.inputs = append(s.inputs, values...)
+}
+
+// Solve returns values for the symbols such that all constraints hold.
+func (s *Symbolic) Solve(constraints ...Constraint) (map[string]int, bool) {
+	domains := make(map[string]interval, len(s.domains))
+	for x, d := range s.domains {
+		domains[x] = d
+	}
+	lcs := make([]linearConstraint, len(constraints))
+	for i, c := range constraints {
+		lcs[i] = newLinearConstraint(c)
+	}
+	return solve(domains, lcs)
+}
+
+// feasible is a quick check that constraints may hold, with propagation
+// only.
+func (s *Symbolic) feasible(constraints []Constraint) bool {
+	domains := make(map[string]interval, len(s.domains))
+	for x, d := range s.domains {
+		domains[x] = d
+	}
+	lcs := make([]linearConstraint, len(constraints))
+	for i, c := range constraints {
+		lcs[i] = newLinearConstraint(c)
+	}
+	return propagateAll(domains, lcs)
+}
+
+// values returns the values that e may take in path p.
+func (s *Symbolic) values(p *Path, e *Expr) ([]int, error) {
+	l, ok := linearize(e)
+	if !ok {
+		return nil, fmt.Errorf("Can't enumerate values of %v", e)
+	}
+	domains := make(map[string]interval, len(s.domains))
+	for x, d := range s.domains {
+		domains[x] = d
+	}
+	lcs := make([]linearConstraint, len(p.Constraints))
+	for i, c := range p.Constraints {
+		lcs[i] = newLinearConstraint(c)
+	}
+	if !propagateAll(domains, lcs) {
+		return nil, nil
+	}
+	lo, hi := l.bounds(domains)
+	if hi-lo >= maxConcretize {
+		return nil, fmt.Errorf("Too many values for %v: %d to %d", e, lo, hi)
+	}
+	var values []int
+	for v := lo; v <= hi; v++ {
+		c := Assert(NewOp(Equals, e, NewConst(v)))
+		if _, ok := p.Solve(c); ok {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// effect is the result of an instruction, possibly depending on symbols.
+// Addr is nil if the instruction doesn't write.
+type effect struct {
+	addr, value, base, next *Expr
+}
+
+// apply applies the effect to p, forking it for every value of its symbolic
+// parts. Paths where they can't take any value are dropped.
+func (s *Symbolic) apply(p *Path, e effect) []*Path {
+	for _, part := range []*Expr{e.addr, e.base, e.next} {
+		if part == nil {
+			continue
+		}
+		if _, ok := part.IsConst(); ok {
+			continue
+		}
+		values, err := s.values(p, part)
+		if err != nil {
+			p.fail(err)
+			return []*Path{p}
+		}
+		var paths []*Path
+		for i, v := range values {
+			q := p
+			if i < len(values)-1 {
+				q = p.fork()
+			}
+			q.Constraints = append(q.Constraints, Assert(NewOp(Equals, part, NewConst(v))))
+			paths = append(paths, s.apply(q, e.fix(part, NewConst(v)))...)
+		}
+		return paths
+	}
+	if e.addr != nil {
+		addr, _ := e.addr.IsConst()
+		if addr < 0 {
+			p.fail(&InvalidAddressError{Address: p.ip, Instruction: p.opcodeWord(), Target: addr, Registers: p.Registers()})
+			return []*Path{p}
+		}
+		p.store(addr, e.value)
+	}
+	base, _ := e.base.IsConst()
+	p.rb += base
+	p.ip, _ = e.next.IsConst()
+	p.Steps++
+	return []*Path{p}
+}
+
+// fix replaces part with v, in every part where it appears.
+func (e effect) fix(part, v *Expr) effect {
+	for _, field := range []**Expr{&e.addr, &e.base, &e.next} {
+		if *field == part {
+			*field = v
+		}
+	}
+	return e
+}
+
+func (p *Path) opcodeWord() int {
+	v, _ := p.load(p.ip).IsConst()
+	return v
+}
+
+// step executes the instruction at the path's instruction pointer, returning
+// the paths that follow from it.
+func (s *Symbolic) step(p *Path) []*Path {
+	if p.Steps >= s.MaxSteps {
+		p.fail(&InstructionLimitError{Address: p.ip, Limit: s.MaxSteps, Registers: p.Registers()})
+		return []*Path{p}
+	}
+	if p.ip < 0 {
+		p.fail(&InvalidAddressError{Address: p.ip, Target: p.ip, Registers: p.Registers()})
+		return []*Path{p}
+	}
+	word, ok := p.load(p.ip).IsConst()
+	if !ok {
+		p.fail(fmt.Errorf("Symbolic instruction %v @ %d (%v)", p.load(p.ip), p.ip, p.Registers()))
+		return []*Path{p}
+	}
+	opcode, modes, numParams, err := decodeModes(word)
+	if err != nil {
+		switch e := err.(type) {
+		case *InvalidOpcodeError:
+			e.Address, e.Registers = p.ip, p.Registers()
+		case *InvalidModeError:
+			e.Address, e.Registers = p.ip, p.Registers()
+		}
+		p.fail(err)
+		return []*Path{p}
+	}
+	expectedModes := expectedModesTable[opcode]
+	var params [maxParams]*Expr
+	for i := 0; i < numParams; i++ {
+		param := p.load(p.ip + 1 + i)
+		switch modes[i] {
+		case Immediate:
+			if expectedModes[i] == Address {
+				p.fail(&InvalidModeError{Address: p.ip, Instruction: word, Param: i + 1, Mode: int(Immediate), Registers: p.Registers()})
+				return []*Path{p}
+			}
+		case Relative:
+			param = NewOp(Add, NewConst(p.rb), param)
+			fallthrough
+		case Address:
+			if expectedModes[i] == Immediate {
+				param = p.read(param)
+			}
+		}
+		params[i] = param
+	}
+	e := effect{base: zeroExpr, next: NewConst(p.ip + numParams + 1)}
+	switch opcode {
+	case Add, Mul, LessThan, Equals:
+		e.addr, e.value = params[2], NewOp(opcode, params[0], params[1])
+	case Input:
+		if p.inputs == len(s.inputs) {
+			p.Status, p.done = NeedsInput, true
+			return []*Path{p}
+		}
+		e.addr, e.value = params[0], s.inputs[p.inputs]
+		p.inputs++
+	case Output:
+		p.Outputs = append(p.Outputs, params[0])
+	case OffsetRelBase:
+		e.base = params[0]
+	case Halt:
+		p.Status, p.done = Halted, true
+		return []*Path{p}
+	case JumpIfNonZero, JumpIfZero:
+		cond := params[0]
+		taken := Constraint{cond, opcode == JumpIfNonZero}
+		if v, ok := cond.IsConst(); ok {
+			if (v != 0) == taken.NonZero {
+				e.next = params[1]
+			}
+			break
+		}
+		notTaken := Constraint{cond, !taken.NonZero}
+		var paths []*Path
+		if jump := append(p.Constraints, taken); s.feasible(jump) {
+			q := p.fork()
+			q.Constraints = append(q.Constraints, taken)
+			jumped := e
+			jumped.next = params[1]
+			paths = append(paths, s.apply(q, jumped)...)
+		}
+		if s.feasible(append(p.Constraints, notTaken)) {
+			p.Constraints = append(p.Constraints, notTaken)
+			paths = append(paths, s.apply(p, e)...)
+		}
+		return paths
+	}
+	return s.apply(p, e)
+}
+
+// Run explores every path of the program, returning them sorted by the
+// order in which they finished. It returns an error if there are more than
+// MaxPaths paths, along with the paths finished so far.
+func (s *Symbolic) Run() ([]*Path, error) {
+	if s.MaxPaths <= 0 {
+		s.MaxPaths = DefaultMaxPaths
+	}
+	if s.MaxSteps <= 0 {
+		s.MaxSteps = DefaultMaxSteps
+	}
+	work := []*Path{{s: s, memory: s.memory, shared: true}}
+	var finished []*Path
+	for len(work) > 0 {
+		p := work[len(work)-1]
+		work = work[:len(work)-1]
+		for _, next := range s.step(p) {
+			if next.done {
+				finished = append(finished, next)
+			} else {
+				work = append(work, next)
+			}
+		}
+		if len(finished)+len(work) > s.MaxPaths {
+			return finished, fmt.Errorf("Path limit of %d reached", s.MaxPaths)
+		}
+	}
+	return finished, nil
+}
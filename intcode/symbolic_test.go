@@ -0,0 +1,50 @@
+package intcode
+
+import "testing"
+
+// day2Target is the output searched for in day 2, part 2.
+const day2Target = 19690720
+
+// day2BruteForce tries all nouns and verbs, returning 100*noun+verb for the
+// first that outputs target.
+func day2BruteForce(t *testing.T, target int) int {
+	for noun := 0; noun <= 99; noun++ {
+		for verb := 0; verb <= 99; verb++ {
+			program := ParseProgram(day2Input)
+			program[1], program[2] = noun, verb
+			c := NewComputer(program)
+			if _, err := c.RunWith(); err != nil {
+				continue
+			}
+			if c.Snapshot().Memory[0] == target {
+				return 100*noun + verb
+			}
+		}
+	}
+	t.Fatalf("No noun and verb output %d", target)
+	return 0
+}
+
+func TestSymbolicDay2(t *testing.T) {
+	s := NewSymbolic(ParseProgram(day2Input))
+	s.SetMemory(1, s.Symbol("noun", 0, 99))
+	s.SetMemory(2, s.Symbol("verb", 0, 99))
+	paths, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := -1
+	for _, p := range paths {
+		if p.Err != nil {
+			t.Fatal(p.Err)
+		}
+		target := NewOp(Equals, p.Memory(0), NewConst(day2Target))
+		if env, ok := p.Solve(Assert(target)); ok {
+			got = 100*env["noun"] + env["verb"]
+			break
+		}
+	}
+	if want := day2BruteForce(t, day2Target); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
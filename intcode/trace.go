@@ -0,0 +1,154 @@
+package intcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+type MemoryWrite struct {
+	Address int `json:"addr"`
+	Old     int `json:"old"`
+	New     int `json:"new"`
+}
+
+// TraceEvent describes an executed instruction. Operands are resolved: values
+// for read params and addresses for write params. RelativeBase is the value
+// before execution, and Next is the instruction pointer after it.
+type TraceEvent struct {
+	Count        int             `json:"count"`
+	Address      int             `json:"addr"`
+	Opcode       InstructionType `json:"opcode"`
+	Mnemonic     string          `json:"op"`
+	Words        []int           `json:"words"`
+	Operands     []int           `json:"operands"`
+	RelativeBase int             `json:"rb"`
+	Next         int             `json:"next"`
+	Writes       []MemoryWrite   `json:"writes,omitempty"`
+	Input        *int            `json:"in,omitempty"`
+	Output       *int            `json:"out,omitempty"`
+}
+
+func (e *TraceEvent) String() string {
+	return fmt.Sprintf("@%d: %s %v\t(%v/%d)", e.Address, e.Mnemonic, e.Operands, e.Words, e.RelativeBase)
+}
+
+// Tracer receives an event for every instruction a computer executes. Events
+// are not reused, so tracers may retain them.
+type Tracer interface {
+	Trace(e *TraceEvent)
+}
+
+type multiTracer []Tracer
+
+func (m multiTracer) Trace(e *TraceEvent) {
+	for _, t := range m {
+		t.Trace(e)
+	}
+}
+
+// MultiTracer sends events to all given tracers, in order.
+func MultiTracer(tracers ...Tracer) Tracer {
+	return multiTracer(tracers)
+}
+
+type TextTracer struct {
+	w io.Writer
+}
+
+// NewTextTracer writes one line per event in the same format as Debug.
+func NewTextTracer(w io.Writer) *TextTracer {
+	return &TextTracer{w}
+}
+
+func (t *TextTracer) Trace(e *TraceEvent) {
+	fmt.Fprintln(t.w, e)
+}
+
+var debugTracer = NewTextTracer(os.Stdout)
+
+type JSONTracer struct {
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONTracer writes events to w as JSON lines.
+func NewJSONTracer(w io.Writer) *JSONTracer {
+	return &JSONTracer{enc: json.NewEncoder(w)}
+}
+
+func (t *JSONTracer) Trace(e *TraceEvent) {
+	if t.err != nil {
+		return
+	}
+	t.err = t.enc.Encode(e)
+}
+
+// Err returns the first error found while writing events.
+func (t *JSONTracer) Err() error {
+	return t.err
+}
+
+// RingTracer holds the last N events, to be inspected after an error.
+type RingTracer struct {
+	events []*TraceEvent
+	next   int
+	full   bool
+}
+
+func NewRingTracer(n int) *RingTracer {
+	return &RingTracer{events: make([]*TraceEvent, n)}
+}
+
+func (t *RingTracer) Trace(e *TraceEvent) {
+	if len(t.events) == 0 {
+		return
+	}
+	t.events[t.next] = e
+	t.next = (t.next + 1) % len(t.events)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+// Events returns the retained events, oldest first.
+func (t *RingTracer) Events() []*TraceEvent {
+	if !t.full {
+		return append([]*TraceEvent(nil), t.events[:t.next]...)
+	}
+	return append(append([]*TraceEvent(nil), t.events[t.next:]...), t.events[:t.next]...)
+}
+
+func (t *RingTracer) Dump(w io.Writer) error {
+	for _, e := range t.Events() {
+		if _, err := fmt.Fprintf(w, "%d\t%v\n", e.Count, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Computer) tracer() Tracer {
+	if !c.Debug {
+		return c.Tracer
+	}
+	if c.Tracer == nil {
+		return debugTracer
+	}
+	return MultiTracer(debugTracer, c.Tracer)
+}
+
+func (c *Computer) newTraceEvent(opcode InstructionType, numParams int, params []int) *TraceEvent {
+	operands := make([]int, numParams)
+	copy(operands, params)
+	return &TraceEvent{
+		Count:        c.instructionCount,
+		Address:      c.instructionPointer,
+		Opcode:       opcode,
+		Mnemonic:     instructionNames[opcode],
+		Words:        c.debugInstructions(numParams),
+		Operands:     operands,
+		RelativeBase: c.relativeBase,
+	}
+}
@@ -0,0 +1,126 @@
+package intcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// doubleOnce reads a value and outputs its double.
+const doubleOnce = "3,9,1002,9,2,9,4,9,99,0"
+
+func trace(t *testing.T, tracer Tracer) {
+	t.Helper()
+	c := NewComputer(ParseProgram(doubleOnce))
+	c.Tracer = tracer
+	if out, err := c.RunWith(5); err != nil || !reflect.DeepEqual(out, []int{10}) {
+		t.Fatalf("got %v, %v, want [10]", out, err)
+	}
+}
+
+func TestTextTracer(t *testing.T) {
+	var buf bytes.Buffer
+	trace(t, NewTextTracer(&buf))
+	want := strings.Join([]string{
+		"@0: in [9]\t([3 9]/0)",
+		"@2: mul [5 2 9]\t([1002 9 2 9]/0)",
+		"@6: out [10]\t([4 9]/0)",
+		"@8: halt []\t([99]/0)",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSONTracer(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewJSONTracer(&buf)
+	trace(t, tracer)
+	if err := tracer.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		`{"count":0,"addr":0,"opcode":3,"op":"in","words":[3,9],"operands":[9],"rb":0,"next":2,"writes":[{"addr":9,"old":0,"new":5}],"in":5}`,
+		`{"count":1,"addr":2,"opcode":2,"op":"mul","words":[1002,9,2,9],"operands":[5,2,9],"rb":0,"next":6,"writes":[{"addr":9,"old":5,"new":10}]}`,
+		`{"count":2,"addr":6,"opcode":4,"op":"out","words":[4,9],"operands":[10],"rb":0,"next":8,"out":10}`,
+		`{"count":3,"addr":8,"opcode":99,"op":"halt","words":[99],"operands":[],"rb":0,"next":8}`,
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	// Each line decodes back into the event.
+	dec := json.NewDecoder(strings.NewReader(want))
+	var e TraceEvent
+	if err := dec.Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Input == nil || *e.Input != 5 || e.Output != nil {
+		t.Errorf("first event has input %v and output %v, want 5 and none", e.Input, e.Output)
+	}
+}
+
+type failingWriter struct{ n int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.n++
+	return 0, errors.New("disk full")
+}
+
+func TestJSONTracerStopsOnError(t *testing.T) {
+	w := new(failingWriter)
+	tracer := NewJSONTracer(w)
+	trace(t, tracer)
+	if err := tracer.Err(); err == nil || err.Error() != "disk full" {
+		t.Errorf("got error %v, want disk full", err)
+	}
+	if w.n != 1 {
+		t.Errorf("got %d writes, want 1", w.n)
+	}
+}
+
+func TestRingTracer(t *testing.T) {
+	tests := []struct {
+		size   int
+		events int
+		want   []int
+	}{
+		{0, 5, nil},
+		{3, 0, nil},
+		{3, 2, []int{0, 1}},
+		{3, 3, []int{0, 1, 2}},
+		{3, 4, []int{1, 2, 3}},
+		{3, 7, []int{4, 5, 6}},
+		{3, 8, []int{5, 6, 7}},
+	}
+	for _, test := range tests {
+		tracer := NewRingTracer(test.size)
+		for i := 0; i < test.events; i++ {
+			tracer.Trace(&TraceEvent{Count: i})
+		}
+		var got []int
+		for _, e := range tracer.Events() {
+			got = append(got, e.Count)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ring of %d after %d events: got %v, want %v", test.size, test.events, got, test.want)
+		}
+	}
+}
+
+func TestRingTracerDump(t *testing.T) {
+	tracer := NewRingTracer(2)
+	trace(t, tracer)
+	var buf bytes.Buffer
+	if err := tracer.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "2\t@6: out [10]\t([4 9]/0)\n3\t@8: halt []\t([99]/0)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,344 @@
+package intcode
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// translatedHeadroom is the number of words allocated after the program by
+// translated functions, before falling back to the interpreter to grow it.
+const translatedHeadroom = 1 << 12
+
+// Translate writes Go source of package pkg with a function
+//
+//	func name(in intcode.IntReader, out intcode.IntWriter) error
+//
+// that runs program like Computer.Run, but with each basic block compiled to
+// Go code in a switch over the program counter.
+//
+// The translated code resumes in the interpreter, from the same state, when
+// it can't go on by itself: a write hits an instruction, a jump goes to an
+// address that isn't the start of a translated block, an address is outside
+// of the allocated memory or the input is exhausted. In the last case the
+// interpreter calls NextInt again, which must keep returning false.
+// Parameters that the program overwrites, like addresses patched by the
+// program itself, are read from memory instead of causing a fallback.
+//
+// Code is discovered like in BuildCFG. If the program has indirect jumps,
+// data words holding the address of a valid instruction are also taken as
+// possible jump targets.
+func Translate(w io.Writer, program []int, pkg, name string) error {
+	t := newTranslator(program, name)
+	t.emitFile(pkg)
+	src, err := format.Source([]byte(t.b.String()))
+	if err != nil {
+		return fmt.Errorf("Formatting translated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type translator struct {
+	g       *CFG
+	program []int
+	name    string
+	// dynamic are parameter words that the program overwrites, so they are
+	// read from memory.
+	dynamic map[int]bool
+	// constant are the words compiled into the translation.
+	constant map[int]bool
+	b        strings.Builder
+}
+
+// jumpTableRoots returns the data words that hold the address of a valid
+// instruction outside of the code known so far.
+func jumpTableRoots(program []int, g *CFG) []int {
+	hasIndirect := false
+	for _, block := range g.Blocks {
+		hasIndirect = hasIndirect || block.Indirect
+	}
+	if !hasIndirect {
+		return nil
+	}
+	var roots []int
+	for addr, v := range program {
+		if g.IsCode(addr) || g.IsCode(v) {
+			continue
+		}
+		if _, ok := decodeAt(program, v); ok {
+			roots = append(roots, v)
+		}
+	}
+	return roots
+}
+
+func newTranslator(program []int, name string) *translator {
+	g := BuildCFG(program)
+	if roots := jumpTableRoots(program, g); len(roots) > 0 {
+		g = buildCFG(program, roots)
+	}
+	t := &translator{
+		g:        g,
+		program:  program,
+		name:     name,
+		dynamic:  make(map[int]bool),
+		constant: make(map[int]bool),
+	}
+	for addr := range g.code {
+		t.constant[addr] = true
+	}
+	for _, instr := range g.instrs {
+		j := writeParam(instr)
+		if j < 0 || instr.modes[j] != Address {
+			continue
+		}
+		target := instr.params[j]
+		if _, isOpcode := g.instrs[target]; g.IsCode(target) && !isOpcode {
+			t.dynamic[target] = true
+			delete(t.constant, target)
+		}
+	}
+	return t
+}
+
+func (t *translator) line(format string, args ...interface{}) {
+	fmt.Fprintf(&t.b, format, args...)
+	t.b.WriteString("\n")
+}
+
+func (t *translator) emitFile(pkg string) {
+	t.line("// Code generated by intcode translate. DO NOT EDIT.")
+	t.line("")
+	t.line("package %s", pkg)
+	t.line("")
+	t.line(`import "brunokim.xyz/advent-of-code-2019/intcode"`)
+	t.line("")
+	t.line("var %sProgram = [...]int{", t.name)
+	for i := 0; i < len(t.program); i += 16 {
+		end := i + 16
+		if end > len(t.program) {
+			end = len(t.program)
+		}
+		t.line("%s,", joinInts(t.program[i:end], ", "))
+	}
+	t.line("}")
+	t.line("")
+	t.emitCodeMap()
+	t.line("")
+	t.line("func %s(in intcode.IntReader, out intcode.IntWriter) error {", t.name)
+	t.line("mem := make([]int, len(%sProgram)+%d)", t.name, translatedHeadroom)
+	t.line("copy(mem, %sProgram[:])", t.name)
+	t.line("pc, rb := 0, 0")
+	t.line("for {")
+	t.line("switch pc {")
+	for i, block := range t.g.Blocks {
+		var next *BasicBlock
+		if i+1 < len(t.g.Blocks) {
+			next = t.g.Blocks[i+1]
+		}
+		t.emitBlock(block, next)
+	}
+	t.line("default:")
+	t.line("return %s(mem, pc, rb, in, out)", t.fallback())
+	t.line("}")
+	t.line("}")
+	t.line("}")
+	t.line("")
+	t.line("// %s resumes in the interpreter from the given state.", t.fallback())
+	t.line("func %s(mem []int, pc, rb int, in intcode.IntReader, out intcode.IntWriter) error {", t.fallback())
+	t.line("c := intcode.NewComputer(nil)")
+	t.line("c.Restore(&intcode.Snapshot{Memory: mem, InstructionPointer: pc, RelativeBase: rb})")
+	t.line("return c.Run(in, out)")
+	t.line("}")
+}
+
+func (t *translator) fallback() string {
+	return t.name + "Fallback"
+}
+
+func (t *translator) codeMap() string {
+	return t.name + "Code"
+}
+
+// emitCodeMap emits a table with the constant words, as ranges.
+func (t *translator) emitCodeMap() {
+	var addrs []int
+	for addr := range t.constant {
+		addrs = append(addrs, addr)
+	}
+	sort.Ints(addrs)
+	t.line("// %s marks the words compiled into %s, which can't be written.", t.codeMap(), t.name)
+	t.line("var %s = func() []bool {", t.codeMap())
+	t.line("code := make([]bool, len(%sProgram))", t.name)
+	t.line("for _, r := range [][2]int{")
+	for i := 0; i < len(addrs); {
+		j := i + 1
+		for j < len(addrs) && addrs[j] == addrs[j-1]+1 {
+			j++
+		}
+		t.line("{%d, %d},", addrs[i], addrs[j-1]+1)
+		i = j
+	}
+	t.line("} {")
+	t.line("for i := r[0]; i < r[1]; i++ {")
+	t.line("code[i] = true")
+	t.line("}")
+	t.line("}")
+	t.line("return code")
+	t.line("}()")
+}
+
+func (t *translator) resume(addr string) string {
+	return fmt.Sprintf("return %s(mem, %s, rb, in, out)", t.fallback(), addr)
+}
+
+// address returns the expression of the address accessed by the i-th
+// parameter, and whether it's known to be within the program.
+func (t *translator) address(instr instruction, i int) (string, bool) {
+	word := instr.address + 1 + i
+	if t.dynamic[word] {
+		if instr.modes[i] == Relative {
+			return fmt.Sprintf("rb+mem[%d]", word), false
+		}
+		return fmt.Sprintf("mem[%d]", word), false
+	}
+	v := instr.params[i]
+	if instr.modes[i] == Relative {
+		if v < 0 {
+			return fmt.Sprintf("rb-%d", -v), false
+		}
+		return fmt.Sprintf("rb+%d", v), false
+	}
+	return strconv.Itoa(v), v >= 0 && v < len(t.program)
+}
+
+// value returns the expression of the value of the i-th parameter.
+func (t *translator) value(instr instruction, i int) string {
+	if instr.modes[i] == Immediate {
+		word := instr.address + 1 + i
+		if t.dynamic[word] {
+			return fmt.Sprintf("mem[%d]", word)
+		}
+		return strconv.Itoa(instr.params[i])
+	}
+	addr, _ := t.address(instr, i)
+	return "mem[" + addr + "]"
+}
+
+// emitChecks falls back to the interpreter before executing instr if any of
+// its addresses is outside of memory.
+func (t *translator) emitChecks(instr instruction) {
+	for i, mode := range instr.modes {
+		if mode == Immediate {
+			continue
+		}
+		if addr, static := t.address(instr, i); !static {
+			t.line("if uint(%s) >= uint(len(mem)) {", addr)
+			t.line("%s", t.resume(strconv.Itoa(instr.address)))
+			t.line("}")
+		}
+	}
+}
+
+// emitStore stores value at the i-th parameter of instr, falling back to
+// the interpreter after it if the write hits translated code. It returns
+// whether the write always falls back.
+func (t *translator) emitStore(instr instruction, i int, value string) bool {
+	addr, static := t.address(instr, i)
+	t.line("mem[%s] = %s", addr, value)
+	next := strconv.Itoa(instr.address + instr.size())
+	if static {
+		if t.constant[instr.params[i]] {
+			t.line("%s", t.resume(next))
+			return true
+		}
+		return false
+	}
+	t.line("if a := %s; a < len(%s) && %s[a] {", addr, t.codeMap(), t.codeMap())
+	t.line("%s", t.resume(next))
+	t.line("}")
+	return false
+}
+
+func (t *translator) emitBlock(block *BasicBlock, next *BasicBlock) {
+	t.line("case %d:", block.Start)
+	for _, addr := range block.Instructions {
+		instr := t.g.instrs[addr]
+		t.line("// %d: %v", addr, instr)
+		t.emitChecks(instr)
+		resumed := false
+		switch instr.opcode {
+		case Add:
+			resumed = t.emitStore(instr, 2, t.value(instr, 0)+" + "+t.value(instr, 1))
+		case Mul:
+			resumed = t.emitStore(instr, 2, t.value(instr, 0)+" * "+t.value(instr, 1))
+		case LessThan, Equals:
+			op := "<"
+			if instr.opcode == Equals {
+				op = "=="
+			}
+			t.line("if %s %s %s {", t.value(instr, 0), op, t.value(instr, 1))
+			t.emitStore(instr, 2, "1")
+			t.line("} else {")
+			resumed = t.emitStore(instr, 2, "0")
+			t.line("}")
+		case Input:
+			t.line("if v, ok := in.NextInt(); ok {")
+			resumed = t.emitStore(instr, 0, "v")
+			t.line("} else {")
+			t.line("%s", t.resume(strconv.Itoa(addr)))
+			t.line("}")
+		case Output:
+			t.line("out.PushInt(%s)", t.value(instr, 0))
+		case OffsetRelBase:
+			t.line("rb += %s", t.value(instr, 0))
+		case Halt:
+			t.line("return nil")
+			return
+		case JumpIfNonZero, JumpIfZero:
+			t.emitJump(instr, block, next)
+			return
+		}
+		if resumed {
+			return
+		}
+	}
+	t.emitFallthrough(block, next)
+}
+
+func (t *translator) emitFallthrough(block *BasicBlock, next *BasicBlock) {
+	if next != nil && next.Start == block.End {
+		t.line("fallthrough")
+		return
+	}
+	t.line("pc = %d", block.End)
+}
+
+func (t *translator) emitJump(instr instruction, block *BasicBlock, next *BasicBlock) {
+	taken, falls := instr.branches()
+	if t.dynamic[instr.address+1] {
+		taken, falls = true, true
+	}
+	target := t.value(instr, 1)
+	if !taken {
+		t.emitFallthrough(block, next)
+		return
+	}
+	if !falls {
+		t.line("pc = %s", target)
+		return
+	}
+	op := "!="
+	if instr.opcode == JumpIfZero {
+		op = "=="
+	}
+	t.line("if %s %s 0 {", t.value(instr, 0), op)
+	t.line("pc = %s", target)
+	t.line("continue")
+	t.line("}")
+	t.emitFallthrough(block, next)
+}
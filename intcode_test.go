@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"brunokim.xyz/advent-of-code-2019/intcode"
+)
+
+func TestInterpreterConfigsAgree(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		inputs []int
+	}{
+		{"day9/part1", day9Input, []int{1}},
+		{"day9/part2", day9Input, []int{2}},
+		{"day13/part1", day13Input, nil},
+	}
+	configs := []struct {
+		name      string
+		configure func(c *intcode.Computer)
+	}{
+		{"nocache", noCache},
+		{"nofusion", noFusion},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			program := intcode.ParseProgram(test.input)
+			c := intcode.NewComputer(program)
+			want, err := c.RunWith(test.inputs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantCount := c.InstructionCount()
+			for _, config := range configs {
+				c := intcode.NewComputer(program)
+				config.configure(c)
+				got, err := c.RunWith(test.inputs...)
+				if err != nil {
+					t.Fatalf("%s: %v", config.name, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: outputs differ from cached run", config.name)
+				}
+				if count := c.InstructionCount(); count != wantCount {
+					t.Errorf("%s: executed %d instructions, want %d", config.name, count, wantCount)
+				}
+			}
+		})
+	}
+}
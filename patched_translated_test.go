@@ -0,0 +1,64 @@
+// Code generated by intcode translate. DO NOT EDIT.
+
+package main
+
+import "brunokim.xyz/advent-of-code-2019/intcode"
+
+var patchedOutputsProgram = [...]int{
+	104, 7, 1001, 1, 1, 1, 1007, 1, 10, 20, 1005, 20, 0, 1101, 0, 99,
+	17, 104, 1, 99, 0,
+}
+
+// patchedOutputsCode marks the words compiled into patchedOutputs, which can't be written.
+var patchedOutputsCode = func() []bool {
+	code := make([]bool, len(patchedOutputsProgram))
+	for _, r := range [][2]int{
+		{0, 1},
+		{2, 20},
+	} {
+		for i := r[0]; i < r[1]; i++ {
+			code[i] = true
+		}
+	}
+	return code
+}()
+
+func patchedOutputs(in intcode.IntReader, out intcode.IntWriter) error {
+	mem := make([]int, len(patchedOutputsProgram)+4096)
+	copy(mem, patchedOutputsProgram[:])
+	pc, rb := 0, 0
+	for {
+		switch pc {
+		case 0:
+			// 0: out #7
+			out.PushInt(mem[1])
+			// 2: add [1], #1, [1]
+			mem[1] = mem[1] + 1
+			// 6: < [1], #10, [20]
+			if mem[1] < 10 {
+				mem[20] = 1
+			} else {
+				mem[20] = 0
+			}
+			// 10: jinz [20], #0
+			if mem[20] != 0 {
+				pc = 0
+				continue
+			}
+			fallthrough
+		case 13:
+			// 13: add #0, #99, [17]
+			mem[17] = 0 + 99
+			return patchedOutputsFallback(mem, 17, rb, in, out)
+		default:
+			return patchedOutputsFallback(mem, pc, rb, in, out)
+		}
+	}
+}
+
+// patchedOutputsFallback resumes in the interpreter from the given state.
+func patchedOutputsFallback(mem []int, pc, rb int, in intcode.IntReader, out intcode.IntWriter) error {
+	c := intcode.NewComputer(nil)
+	c.Restore(&intcode.Snapshot{Memory: mem, InstructionPointer: pc, RelativeBase: rb})
+	return c.Run(in, out)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"brunokim.xyz/advent-of-code-2019/intcode"
+)
+
+//go:generate go run ./cmd/intcode translate -func patchedOutputs -const patchedOutputsInput -o patched_translated_test.go translate_test.go
+
+// patchedOutputsInput outputs 7, 8 and 9 by incrementing the param of its
+// output instruction, and then writes a halt over the last output.
+const patchedOutputsInput = "104,7,1001,1,1,1,1007,1,10,20,1005,20,0,1101,0,99,17,104,1,99,0"
+
+func TestDay7TranslatedAgrees(t *testing.T) {
+	for _, phases := range permutations([]int{0, 1, 2, 3, 4}) {
+		want, err := day7Part1Instance(phases...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := day7TranslatedInstance(phases...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%v: translated got %d, want %d", phases, got, want)
+		}
+	}
+}
+
+func TestTranslatedFilesAreUpToDate(t *testing.T) {
+	tests := []struct {
+		path  string
+		input string
+		name  string
+	}{
+		{"day7_translated.go", day7Input, "day7Translated"},
+		{"patched_translated_test.go", patchedOutputsInput, "patchedOutputs"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := intcode.Translate(&buf, intcode.ParseProgram(test.input), "main", test.name); err != nil {
+			t.Fatal(err)
+		}
+		want, err := ioutil.ReadFile(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%s is stale, run go generate", test.path)
+		}
+	}
+}
+
+func TestSelfModifyingTranslationFallsBack(t *testing.T) {
+	want, err := intcode.NewComputer(intcode.ParseProgram(patchedOutputsInput)).RunWith()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, []int{7, 8, 9}) {
+		t.Fatalf("interpreted got %v, want [7 8 9]", want)
+	}
+	var got intQueue
+	if err := patchedOutputs(&intQueue{}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual([]int(got), want) {
+		t.Errorf("translated got %v, want %v", got, want)
+	}
+}